@@ -3,33 +3,44 @@ package main
 import (
 	"strings"
 
+	"github.com/quasilyte/decipherism-game/leveldata"
 	resource "github.com/quasilyte/ebitengine-resource"
 	"github.com/quasilyte/ge"
 )
 
+var theSchemaTileset *leveldata.Tileset
+
+// theGeneratorWords is a keywords source for the generated puzzles.
+var theGeneratorWords []string
+
 func prepareAssets(ctx *ge.Context) {
-	theStoryModeMap.levels = make(map[string]storyModeLevel)
-	resourceID := rawLastID + 1
-	resourceID = loadLevelsData(ctx, resourceID, "levels/story")
-	resourceID = loadLevelsData(ctx, resourceID, "levels/bonus")
+	// The tileset is required to validate the story levels.
+	loadSchemaElemImages(ctx)
+
+	storyModeMap, err := loadBuiltinCampaign()
+	if err != nil {
+		panic(err) // Builtin campaign should never contain any errors
+	}
+	theStoryModeMap = storyModeMap
+
+	theGeneratorWords = strings.Fields(string(ctx.Loader.LoadRaw(RawGeneratorWordsTXT).Data))
 }
 
-func loadLevelsData(ctx *ge.Context, idSeq resource.RawID, dirname string) resource.RawID {
-	levels, err := gameAssets.ReadDir("_assets/" + dirname)
+func loadSchemaElemImages(ctx *ge.Context) {
+	tileset, err := leveldata.LoadTileset(ctx.Loader.LoadRaw(RawComponentSchemaTilesetJSON).Data)
 	if err != nil {
 		panic(err)
 	}
-	for _, f := range levels {
-		shortName := strings.TrimSuffix(f.Name(), ".json")
-		theStoryModeMap.levels[shortName] = storyModeLevel{
-			name: shortName,
-			id:   idSeq,
-		}
-		ctx.Loader.RawRegistry.Set(idSeq, resource.RawInfo{
-			Path: dirname + "/" + f.Name(),
-		})
-		ctx.Loader.LoadRaw(idSeq)
-		idSeq++
+	theSchemaTileset = tileset
+
+	// Element sprites are declared by the tileset.
+	for _, e := range tileset.Elems() {
+		id := schemaElemImageID(e.TileID)
+		ctx.Loader.ImageRegistry.Set(id, resource.ImageInfo{Path: e.Sprite})
+		ctx.Loader.LoadImage(id)
 	}
-	return idSeq
+}
+
+func schemaElemImageID(tileID int) resource.ImageID {
+	return resource.ImageID(tileID) + componentSchemaImageOffset + 1
 }
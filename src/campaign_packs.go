@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+	"github.com/quasilyte/gmath"
+)
+
+// scanCampaignPacks loads all valid campaign packs from the user data folder.
+// Every pack is a "campaigns" sub-folder that contains a manifest file.
+func scanCampaignPacks(userFolder string) ([]*storyModeMap, error) {
+	campaignsPath := filepath.Join(userFolder, "campaigns")
+	files, err := os.ReadDir(campaignsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*storyModeMap
+	names := make(map[string]string)
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		campaign, err := loadCampaignPack(filepath.Join(campaignsPath, f.Name()))
+		if err != nil {
+			fmt.Printf("[ERROR] load %q campaign: %v\n", f.Name(), err)
+			continue
+		}
+		// The name is used as a save data key, it should be unique.
+		if other, ok := names[campaign.name]; ok {
+			fmt.Printf("[ERROR] load %q campaign: name %q is already used by %q\n", f.Name(), campaign.name, other)
+			continue
+		}
+		names[campaign.name] = f.Name()
+		result = append(result, campaign)
+	}
+
+	return result, nil
+}
+
+// loadBuiltinCampaign loads the story mode campaign from the embedded assets.
+//
+// Unlike the custom campaigns, it's also required to use every level file.
+func loadBuiltinCampaign() (*storyModeMap, error) {
+	fsys, err := fs.Sub(gameAssets, "_assets/levels")
+	if err != nil {
+		return nil, err
+	}
+	campaign, err := decodeCampaign(fsys)
+	if err != nil {
+		return nil, err
+	}
+	unreferenced, err := leveldata.UnreferencedCampaignFiles(campaign, fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(unreferenced) != 0 {
+		return nil, fmt.Errorf("found level files that are not used by the campaign: %s", strings.Join(unreferenced, ", "))
+	}
+	return newStoryModeMap(campaign, fsys)
+}
+
+func loadCampaignPack(dir string) (*storyModeMap, error) {
+	fsys := os.DirFS(dir)
+	campaign, err := decodeCampaign(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return newStoryModeMap(campaign, fsys)
+}
+
+// decodeCampaign loads and validates the campaign manifest from the fsys root.
+func decodeCampaign(fsys fs.FS) (*leveldata.Campaign, error) {
+	manifestData, err := fs.ReadFile(fsys, leveldata.CampaignManifestFilename)
+	if err != nil {
+		return nil, err
+	}
+	campaign, err := leveldata.DecodeCampaign(manifestData)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", leveldata.CampaignManifestFilename, err)
+	}
+	if _, err := leveldata.ValidateCampaign(theSchemaTileset, campaign, fsys); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+func newStoryModeMap(campaign *leveldata.Campaign, fsys fs.FS) (*storyModeMap, error) {
+	result := &storyModeMap{
+		name:   campaign.Name,
+		title:  campaign.Title,
+		levels: make(map[string]storyModeLevel),
+	}
+	for _, c := range campaign.Chapters {
+		chapter := storyModeChapter{
+			name:     c.Name,
+			label:    c.Label,
+			keyword:  c.Keyword,
+			requires: c.Requires,
+			gridPos:  gmath.Vec{X: float64(c.Col), Y: float64(c.Row)},
+		}
+		for _, levelPath := range c.Levels {
+			levelData, err := fs.ReadFile(fsys, levelPath)
+			if err != nil {
+				return nil, err
+			}
+			name := leveldata.CampaignLevelName(levelPath)
+			chapter.levels = append(chapter.levels, name)
+			result.levels[name] = storyModeLevel{name: name, data: levelData}
+		}
+		result.chapters = append(result.chapters, chapter)
+	}
+	return result, nil
+}
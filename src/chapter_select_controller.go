@@ -29,10 +29,11 @@ func (c *chapterSelectController) Init(scene *ge.Scene) {
 	c.scene.AddObject(uiRoot)
 
 	nodeOffset := gmath.Vec{X: 112 + (42 * 4), Y: 36 + (42.5 * 3)}
-	content := calculateContentStatus(c.gameState)
-	for i := range theStoryModeMap.chapters {
-		chapter := &theStoryModeMap.chapters[i]
-		chapterStatus := c.gameState.GetChapterCompletionData(chapter)
+	campaign := c.gameState.campaign
+	content := calculateContentStatus(c.gameState, campaign)
+	for i := range campaign.chapters {
+		chapter := &campaign.chapters[i]
+		chapterStatus := c.gameState.GetChapterCompletionData(campaign, chapter)
 		available := xslices.Contains(content.chapters, chapter.name)
 		pos := nodeOffset.Add(gmath.Vec{X: 42 * (chapter.gridPos.X * 7), Y: 42 * (chapter.gridPos.Y * 7)})
 		if !available {
@@ -64,5 +65,9 @@ func (c *chapterSelectController) Update(delta float64) {
 }
 
 func (c *chapterSelectController) leave() {
+	if c.gameState.campaign != theStoryModeMap {
+		c.scene.Context().ChangeScene(newCustomLevelSelectController(c.gameState))
+		return
+	}
 	c.scene.Context().ChangeScene(newMainMenuController(c.gameState))
 }
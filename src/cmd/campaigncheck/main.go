@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	tilesetPath := flag.String("tileset", "",
+		`path to a schemas.tsj file`)
+	quiet := flag.Bool("quiet", false,
+		`don't print the intermediate keywords`)
+	flag.Parse()
+
+	if *tilesetPath == "" {
+		log.Fatal("--tileset can't be empty")
+	}
+	if len(flag.Args()) != 1 {
+		log.Fatal("expected exactly 1 positional argument: a campaign.json file path")
+	}
+
+	tilesetData, err := os.ReadFile(*tilesetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tileset, err := leveldata.LoadTileset(tilesetData)
+	if err != nil {
+		log.Fatalf("[ERROR] decode tileset file: %v", err)
+	}
+
+	manifestPath := flag.Args()[0]
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := leveldata.DecodeCampaign(manifest)
+	if err != nil {
+		log.Fatalf("[ERROR] decode campaign: %v", err)
+	}
+	fsys := os.DirFS(filepath.Dir(manifestPath))
+
+	// The chains are printed even if they're broken, the errors are reported below.
+	chains, validateErr := leveldata.ValidateCampaign(tileset, c, fsys)
+	if !*quiet {
+		for _, chain := range chains {
+			fmt.Printf("%s: %s\n", chain.Chapter, chain.Keyword)
+			for _, link := range chain.Links {
+				fmt.Printf("  %s: %s -> %s\n", link.Level, link.Input, link.Output)
+			}
+		}
+	}
+	if validateErr != nil {
+		for _, d := range leveldata.ErrorDiagnostics(validateErr) {
+			fmt.Fprintf(os.Stderr, "[%s] %v\n", strings.ToUpper(d.Severity.String()), d)
+		}
+		os.Exit(1)
+	}
+
+	unreferenced, err := leveldata.UnreferencedCampaignFiles(c, fsys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range unreferenced {
+		fmt.Fprintf(os.Stderr, "[WARNING] %q is not referenced by the campaign\n", p)
+	}
+
+	difficulty, err := leveldata.EstimateCampaignDifficulty(tileset, c, fsys, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !*quiet {
+		for _, d := range difficulty {
+			fmt.Printf("%s: difficulty score %.1f\n", d.Chapter, d.Score)
+		}
+	}
+	for _, d := range leveldata.CheckChapterProgression(c, difficulty) {
+		fmt.Fprintf(os.Stderr, "[WARNING] %v\n", d)
+	}
+
+	fmt.Printf("[OK] %d secret keywords are good (checked %d chapters)\n", len(chains), len(c.Chapters))
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+	"github.com/quasilyte/gmath"
+)
+
+type elemCoverage struct {
+	hits     int
+	keywords int
+	branches [2]int
+}
+
+func main() {
+	log.SetFlags(0)
+
+	tilesetPath := flag.String("tileset", "",
+		`path to a schemas.tsj file`)
+	showPaths := flag.Bool("paths", true,
+		`print every keyword path`)
+	showPipes := flag.Bool("pipes", false,
+		`include the plain pipes into the report`)
+	flag.Parse()
+
+	if *tilesetPath == "" {
+		log.Fatal("--tileset can't be empty")
+	}
+	if len(flag.Args()) == 0 {
+		log.Fatal("expected a level file and optional keywords list")
+	}
+
+	tilesetData, err := os.ReadFile(*tilesetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tileset, err := leveldata.LoadTileset(tilesetData)
+	if err != nil {
+		log.Fatalf("[ERROR] decode tileset file: %v", err)
+	}
+
+	levelFilename := flag.Args()[0]
+	levelData, err := os.ReadFile(levelFilename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tmpl, err := leveldata.LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", levelFilename, err)
+	}
+	schema, err := leveldata.NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", levelFilename, err)
+	}
+
+	// The level keywords are used unless they're specified explicitly.
+	keywords := schema.Keywords
+	if len(flag.Args()) > 1 {
+		keywords = flag.Args()[1:]
+	}
+
+	isReported := func(e *leveldata.SchemaElem) bool {
+		switch e.Kind {
+		case leveldata.SimplePipeElem, leveldata.PipeConnect2Elem:
+			return *showPipes
+		default:
+			return true
+		}
+	}
+
+	coverage := make([]elemCoverage, len(schema.Elems))
+	var path []string
+	var visited []bool
+	runner := leveldata.NewSchemaRunner()
+	runner.Trace = func(e *leveldata.SchemaElem, branch int) {
+		c := &coverage[e.ElemID]
+		c.hits++
+		if !visited[e.ElemID] {
+			visited[e.ElemID] = true
+			c.keywords++
+		}
+		if branch != -1 {
+			c.branches[branch]++
+		}
+		if *showPaths && isReported(e) {
+			path = append(path, formatStep(e, branch))
+		}
+	}
+
+	hasErrors := false
+	for _, k := range keywords {
+		path = path[:0]
+		visited = make([]bool, len(schema.Elems))
+		output, err := runner.Exec(schema, k)
+		if err != nil {
+			hasErrors = true
+			fmt.Fprintf(os.Stderr, "%q: %v\n", k, err)
+		}
+		if !*showPaths {
+			continue
+		}
+		if err == nil {
+			fmt.Printf("%s -> %s\n", k, output)
+		} else {
+			fmt.Printf("%s -> ?\n", k)
+		}
+		for _, step := range path {
+			fmt.Printf("  %s\n", step)
+		}
+	}
+
+	if *showPaths {
+		fmt.Println()
+	}
+	printCoverageTable(schema, coverage, len(keywords), isReported)
+
+	if hasErrors {
+		os.Exit(1)
+	}
+}
+
+func formatStep(e *leveldata.SchemaElem, branch int) string {
+	s := fmt.Sprintf("%s (row=%d col=%d)", e.TileClass, e.Row, e.Col)
+	switch branch {
+	case 0:
+		s += " -> special"
+	case 1:
+		s += " -> other"
+	}
+	return s
+}
+
+func printCoverageTable(schema *leveldata.ComponentSchema, coverage []elemCoverage, numKeywords int, isReported func(*leveldata.SchemaElem) bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ELEMENT\tROW\tCOL\tHITS\tKEYWORDS\tSPECIAL\tOTHER\t")
+
+	numReported := 0
+	numCovered := 0
+	for _, e := range schema.Elems {
+		if !isReported(e) {
+			continue
+		}
+		numReported++
+		c := coverage[e.ElemID]
+		if c.hits != 0 {
+			numCovered++
+		}
+		special := "-"
+		other := "-"
+		if e.Kind == leveldata.IfElem {
+			special = fmt.Sprint(c.branches[0])
+			other = fmt.Sprint(c.branches[1])
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d/%d\t%s\t%s\t\n",
+			e.TileClass, e.Row, e.Col, c.hits, c.keywords, numKeywords, special, other)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d/%d elements are covered\n", numCovered, numReported)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+	"github.com/quasilyte/gmath"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	tilesetPath := flag.String("tileset", "",
+		`path to a schemas.tsj file`)
+	trace := flag.Bool("trace", false,
+		`print every visited element along with the data after it`)
+	flag.Parse()
+
+	if *tilesetPath == "" {
+		log.Fatal("--tileset can't be empty")
+	}
+	if len(flag.Args()) < 2 {
+		log.Fatal("expected a level file and at least 1 word to encode")
+	}
+
+	tilesetData, err := os.ReadFile(*tilesetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tileset, err := leveldata.LoadTileset(tilesetData)
+	if err != nil {
+		log.Fatalf("[ERROR] decode tileset file: %v", err)
+	}
+
+	levelFilename := flag.Args()[0]
+	levelData, err := os.ReadFile(levelFilename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tmpl, err := leveldata.LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", levelFilename, err)
+	}
+	schema, err := leveldata.NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", levelFilename, err)
+	}
+
+	hasErrors := false
+	runner := leveldata.NewSchemaRunner()
+	var traceLines []string
+	if *trace {
+		runner.Trace = func(e *leveldata.SchemaElem, branch int) {
+			traceLines = append(traceLines, fmt.Sprintf("  %4d  %-28s row=%d col=%-2d  %s",
+				len(traceLines), e.TileClass, e.Row, e.Col, runner.Data()))
+		}
+	}
+	for _, word := range flag.Args()[1:] {
+		if err := validateWord(word); err != nil {
+			hasErrors = true
+			fmt.Fprintf(os.Stderr, "%q: %v\n", word, err)
+			continue
+		}
+		traceLines = traceLines[:0]
+		output, err := runner.Exec(schema, word)
+		if err != nil {
+			hasErrors = true
+			fmt.Fprintf(os.Stderr, "%q: %v\n", word, err)
+			// A non-terminating run can be very long, its end is enough to see the loop.
+			if len(traceLines) > maxErrorTraceLines {
+				fmt.Fprintf(os.Stderr, "  ... (%d steps are skipped)\n", len(traceLines)-maxErrorTraceLines)
+				traceLines = traceLines[len(traceLines)-maxErrorTraceLines:]
+			}
+			for _, l := range traceLines {
+				fmt.Fprintln(os.Stderr, l)
+			}
+			continue
+		}
+		fmt.Printf("%s -> %s\n", word, output)
+		for _, l := range traceLines {
+			fmt.Println(l)
+		}
+	}
+
+	if hasErrors {
+		os.Exit(1)
+	}
+}
+
+// maxErrorTraceLines is the number of the last trace lines printed for a failed run.
+const maxErrorTraceLines = 50
+
+// validateWord applies the same rules as the game input does.
+func validateWord(word string) error {
+	if word == "" {
+		return errors.New("the word can't be empty")
+	}
+	if len(word) > leveldata.MaxInputLen {
+		return fmt.Errorf("the word can't be longer than %d letters", leveldata.MaxInputLen)
+	}
+	for _, ch := range []byte(word) {
+		if ch < 'a' || ch > 'z' {
+			return errors.New("only a-z letters are allowed")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package cliutil contains the helpers that are shared by the command-line tools.
+package cliutil
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadWordList reads a file with one word per line.
+// The words are lowercased, the empty lines are skipped.
+func ReadWordList(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		w := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if w != "" {
+			words = append(words, w)
+		}
+	}
+	return words, scanner.Err()
+}
+
+// TilesetSource returns a tileset reference for a Tiled map that is written to outFilename.
+// The reference is relative to the map file directory.
+// An empty outFilename means the current directory.
+func TilesetSource(outFilename, tilesetPath string) (string, error) {
+	outDir := "."
+	if outFilename != "" {
+		outDir = filepath.Dir(outFilename)
+	}
+	// filepath.Rel can't relate an absolute path to a relative one.
+	absOutDir, err := filepath.Abs(outDir)
+	if err != nil {
+		return "", err
+	}
+	absTilesetPath, err := filepath.Abs(tilesetPath)
+	if err != nil {
+		return "", err
+	}
+	source, err := filepath.Rel(absOutDir, absTilesetPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(source), nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/quasilyte/decipherism-game/cmd/internal/cliutil"
+	"github.com/quasilyte/decipherism-game/leveldata"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	tilesetPath := flag.String("tileset", "",
+		`path to a schemas.tsj file`)
+	to := flag.String("to", "native",
+		`output format: native or tiled`)
+	outFilename := flag.String("o", "",
+		`output file name; if empty, the result is printed to stdout`)
+	flag.Parse()
+
+	if *tilesetPath == "" {
+		log.Fatal("--tileset can't be empty")
+	}
+	if len(flag.Args()) != 1 {
+		log.Fatal("expected exactly 1 positional argument, a level file")
+	}
+
+	tilesetData, err := os.ReadFile(*tilesetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tileset, err := leveldata.LoadTileset(tilesetData)
+	if err != nil {
+		log.Fatalf("[ERROR] decode tileset file: %v", err)
+	}
+
+	filename := flag.Args()[0]
+	levelData, err := os.ReadFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tmpl, err := leveldata.LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", filename, err)
+	}
+
+	var result []byte
+	switch *to {
+	case "native":
+		result, err = leveldata.TemplateToNative(tmpl)
+	case "tiled":
+		tilesetSource, srcErr := cliutil.TilesetSource(*outFilename, *tilesetPath)
+		if srcErr != nil {
+			log.Fatalf("[ERROR] locate tileset: %v", srcErr)
+		}
+		result, err = leveldata.TemplateToTilemap(tmpl, tilesetSource)
+	default:
+		log.Fatalf("unexpected --to value: %q", *to)
+	}
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", filename, err)
+	}
+	result = append(result, '\n')
+
+	if *outFilename == "" {
+		os.Stdout.Write(result)
+		return
+	}
+	if err := os.WriteFile(*outFilename, result, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/quasilyte/decipherism-game/cmd/internal/cliutil"
+	"github.com/quasilyte/decipherism-game/leveldata"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	tilesetPath := flag.String("tileset", "",
+		`path to a schemas.tsj file`)
+	wordsPath := flag.String("words", "",
+		`path to a words list file (one word per line) to pick the keywords from`)
+	seed := flag.Int64("seed", 0,
+		`generator seed; if 0, the current time is used`)
+	difficulty := flag.Int("difficulty", 1,
+		`level difficulty, from 1 to 5`)
+	numKeywords := flag.Int("num-keywords", 2,
+		`number of keywords a player needs to decode`)
+	to := flag.String("to", "native",
+		`output format: native, tiled or text`)
+	outFilename := flag.String("o", "",
+		`output file name; if empty, the result is printed to stdout`)
+	flag.Parse()
+
+	if *tilesetPath == "" {
+		log.Fatal("--tileset can't be empty")
+	}
+	if *wordsPath == "" {
+		log.Fatal("--words can't be empty")
+	}
+
+	tilesetData, err := os.ReadFile(*tilesetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tileset, err := leveldata.LoadTileset(tilesetData)
+	if err != nil {
+		log.Fatalf("[ERROR] decode tileset file: %v", err)
+	}
+	words, err := cliutil.ReadWordList(*wordsPath)
+	if err != nil {
+		log.Fatalf("[ERROR] read words list: %v", err)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+		log.Printf("[INFO] using seed %d", *seed)
+	}
+	tmpl, err := leveldata.GenerateLevel(tileset, leveldata.GeneratorConfig{
+		Seed:        *seed,
+		Difficulty:  *difficulty,
+		Words:       words,
+		NumKeywords: *numKeywords,
+	})
+	if err != nil {
+		log.Fatalf("[ERROR] generate level: %v", err)
+	}
+
+	var result []byte
+	switch *to {
+	case "native":
+		result, err = leveldata.TemplateToNative(tmpl)
+	case "tiled":
+		tilesetSource, srcErr := cliutil.TilesetSource(*outFilename, *tilesetPath)
+		if srcErr != nil {
+			log.Fatalf("[ERROR] locate tileset: %v", srcErr)
+		}
+		result, err = leveldata.TemplateToTilemap(tmpl, tilesetSource)
+	case "text":
+		result, err = leveldata.TemplateToText(tmpl)
+	default:
+		log.Fatalf("unexpected --to value: %q", *to)
+	}
+	if err != nil {
+		log.Fatalf("[ERROR] encode level: %v", err)
+	}
+	if *to != "text" {
+		result = append(result, '\n')
+	}
+
+	if *outFilename == "" {
+		os.Stdout.Write(result)
+		return
+	}
+	if err := os.WriteFile(*outFilename, result, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	tilesetPath := flag.String("tileset", "",
+		`path to a schemas.tsj file`)
+	write := flag.Bool("w", false,
+		`write the result to a .txt file next to the level file instead of stdout`)
+	flag.Parse()
+
+	if *tilesetPath == "" {
+		log.Fatal("--tileset can't be empty")
+	}
+	if len(flag.Args()) == 0 {
+		log.Fatal("expected at least 1 positional argument")
+	}
+
+	tilesetData, err := os.ReadFile(*tilesetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tileset, err := leveldata.LoadTileset(tilesetData)
+	if err != nil {
+		log.Fatalf("[ERROR] decode tileset file: %v", err)
+	}
+
+	hasErrors := false
+	for i, filename := range flag.Args() {
+		text, err := convertFile(tileset, filename)
+		if err != nil {
+			hasErrors = true
+			fmt.Fprintf(os.Stderr, "%q: %v\n", filename, err)
+			continue
+		}
+		if *write {
+			outFilename := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".txt"
+			if err := os.WriteFile(outFilename, text, 0o644); err != nil {
+				hasErrors = true
+				fmt.Fprintf(os.Stderr, "%q: %v\n", filename, err)
+			}
+			continue
+		}
+		if len(flag.Args()) > 1 {
+			if i != 0 {
+				fmt.Println()
+			}
+			fmt.Printf("# %s\n", filename)
+		}
+		os.Stdout.Write(text)
+	}
+
+	if hasErrors {
+		os.Exit(1)
+	}
+}
+
+func convertFile(tileset *leveldata.Tileset, filename string) ([]byte, error) {
+	levelData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := leveldata.LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		return nil, err
+	}
+	return leveldata.TemplateToText(tmpl)
+}
@@ -1,20 +1,45 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/quasilyte/decipherism-game/cmd/internal/cliutil"
 	"github.com/quasilyte/decipherism-game/leveldata"
-	"github.com/quasilyte/ge/tiled"
+	"github.com/quasilyte/gmath"
 )
 
+type checkConfig struct {
+	collisions bool
+	lossy      bool
+	lint       bool
+	stats      bool
+	migrate    bool
+	dictionary []string
+}
+
 func main() {
 	log.SetFlags(0)
 
 	tilesetPath := flag.String("tileset", "",
 		`path to a schemas.tsj file`)
+	collisions := flag.Bool("collisions", false,
+		`report keywords that have identical encodings`)
+	dictPath := flag.String("dict", "",
+		`path to a words list file (one word per line) for the --collisions and --stats modes`)
+	lossy := flag.Bool("lossy", false,
+		`report transforms that can map different inputs to the same output`)
+	lint := flag.Bool("lint", false,
+		`report unreachable elements and elements that no keyword executes`)
+	stats := flag.Bool("stats", false,
+		`report the schema difficulty estimation: branches, loop depth, lossy transforms and so on`)
+	migrate := flag.Bool("migrate", false,
+		`upgrade the outdated level files to the latest format version (rewrites them in place)`)
+	format := flag.String("format", "text",
+		`output format: text or json`)
 	flag.Parse()
 
 	if *tilesetPath == "" {
@@ -23,38 +48,210 @@ func main() {
 	if len(flag.Args()) == 0 {
 		log.Fatal("expected at least 1 positional argument")
 	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("unexpected --format value: %q", *format)
+	}
+	if *dictPath != "" && !*collisions && !*stats {
+		log.Fatal("--dict can only be used with --collisions or --stats")
+	}
 
 	tilesetData, err := os.ReadFile(*tilesetPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	tileset, err := tiled.UnmarshalTileset(tilesetData)
+	tileset, err := leveldata.LoadTileset(tilesetData)
 	if err != nil {
 		log.Fatalf("[ERROR] decode tileset file: %v", err)
 	}
 
+	config := checkConfig{
+		collisions: *collisions,
+		lossy:      *lossy,
+		lint:       *lint,
+		stats:      *stats,
+		migrate:    *migrate,
+	}
+	if *dictPath != "" {
+		words, err := cliutil.ReadWordList(*dictPath)
+		if err != nil {
+			log.Fatalf("[ERROR] read dictionary: %v", err)
+		}
+		config.dictionary = words
+	}
+
 	hasErrors := false
+	jsonReport := make([]jsonDiagnostic, 0)
 	for _, filename := range flag.Args() {
-		err := checkFile(tileset, filename)
-		if err != nil {
-			hasErrors = true
-			fmt.Fprintf(os.Stderr, "%q: %v\n", filename, err)
+		for _, d := range checkFile(tileset, filename, config) {
+			if d.Severity == leveldata.SeverityError {
+				hasErrors = true
+			}
+			if *format == "json" {
+				jsonReport = append(jsonReport, jsonDiagnostic{File: filename, Diagnostic: d})
+				continue
+			}
+			printDiagnostic(filename, d)
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(jsonReport); err != nil {
+			log.Fatal(err)
 		}
 	}
 
 	if hasErrors {
 		os.Exit(1)
-	} else {
+	} else if *format == "text" {
 		fmt.Printf("[OK] all files are good (checked %d files)\n", len(flag.Args()))
 	}
 }
 
-func checkFile(tileset *tiled.Tileset, filename string) error {
+type jsonDiagnostic struct {
+	File string `json:"file"`
+	leveldata.Diagnostic
+}
+
+func printDiagnostic(filename string, d leveldata.Diagnostic) {
+	switch d.Severity {
+	case leveldata.SeverityError:
+		fmt.Fprintf(os.Stderr, "%q: %v\n", filename, d)
+	case leveldata.SeverityWarning:
+		fmt.Printf("%q: [WARNING] %v\n", filename, d)
+	default:
+		fmt.Printf("%q: [INFO] %v\n", filename, d)
+	}
+}
+
+func checkFile(tileset *leveldata.Tileset, filename string, config checkConfig) leveldata.DiagnosticList {
 	levelData, err := os.ReadFile(filename)
 	if err != nil {
-		return err
+		return leveldata.ErrorDiagnostics(err)
 	}
 
-	return leveldata.ValidateLevelData(tileset, levelData)
+	var result leveldata.DiagnosticList
+	if config.migrate {
+		migrated, d, err := migrateFile(tileset, filename, levelData)
+		if err != nil {
+			return leveldata.ErrorDiagnostics(err)
+		}
+		if d != nil {
+			result = append(result, *d)
+		}
+		levelData = migrated
+	}
+
+	if err := leveldata.ValidateLevelData(tileset, levelData); err != nil {
+		return leveldata.ErrorDiagnostics(err)
+	}
+
+	if !config.collisions && !config.lossy && !config.lint && !config.stats {
+		return result
+	}
+
+	tmpl, err := leveldata.LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		return leveldata.ErrorDiagnostics(err)
+	}
+	schema, err := leveldata.NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		return leveldata.ErrorDiagnostics(err)
+	}
+
+	if config.lossy {
+		result = append(result, findLossyElems(schema)...)
+	}
+	if config.lint {
+		result = append(result, leveldata.Lint(schema)...)
+	}
+	if config.collisions {
+		result = append(result, checkCollisions(schema, config)...)
+	}
+	if config.stats {
+		result = append(result, schemaStats(schema, config)...)
+	}
+
+	return result
+}
+
+// migrateFile rewrites the level file if its format version is outdated.
+func migrateFile(tileset *leveldata.Tileset, filename string, levelData []byte) ([]byte, *leveldata.Diagnostic, error) {
+	migrated, version, err := leveldata.MigrateLevelData(tileset, levelData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if version == leveldata.LevelFormatVersion {
+		return levelData, nil, nil
+	}
+	// Don't overwrite the file unless the upgraded level is valid.
+	if err := leveldata.ValidateLevelData(tileset, migrated); err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(filename, migrated, 0o644); err != nil {
+		return nil, nil, err
+	}
+	d := schemaDiagnostic(leveldata.SeverityInfo,
+		"migrated from format version %d to %d", version, leveldata.LevelFormatVersion)
+	return migrated, &d, nil
+}
+
+func findLossyElems(schema *leveldata.ComponentSchema) leveldata.DiagnosticList {
+	var result leveldata.DiagnosticList
+	for _, e := range schema.Elems {
+		extra, ok := e.ExtraData.(*leveldata.TransformElemExtra)
+		if !ok || extra.IsInvertible() {
+			continue
+		}
+		result = append(result, leveldata.NewElemDiagnostic(leveldata.SeverityInfo, e, "transform is lossy"))
+	}
+	return result
+}
+
+func checkCollisions(schema *leveldata.ComponentSchema, config checkConfig) leveldata.DiagnosticList {
+	var result leveldata.DiagnosticList
+
+	if len(config.dictionary) != 0 {
+		collisions, err := leveldata.FindDictionaryCollisions(schema, schema.Keywords, config.dictionary)
+		if err != nil {
+			return leveldata.ErrorDiagnostics(err)
+		}
+		for _, c := range collisions {
+			result = append(result, schemaDiagnostic(leveldata.SeverityInfo,
+				"keyword %q collides with %q (both are encoded as %q)", c.Keyword, c.Other, c.Encoded))
+		}
+	}
+
+	collisions, err := leveldata.FindKeywordCollisions(schema, schema.Keywords)
+	if err != nil {
+		return append(result, leveldata.ErrorDiagnostics(err)...)
+	}
+	for _, c := range collisions {
+		result = append(result, schemaDiagnostic(leveldata.SeverityWarning,
+			"keywords %q and %q are both encoded as %q", c.Keyword, c.Other, c.Encoded))
+	}
+
+	return result
+}
+
+func schemaStats(schema *leveldata.ComponentSchema, config checkConfig) leveldata.DiagnosticList {
+	stats, err := leveldata.EstimateDifficulty(schema, config.dictionary)
+	if err != nil {
+		return leveldata.ErrorDiagnostics(err)
+	}
+	d := schemaDiagnostic(leveldata.SeverityInfo,
+		"difficulty score %.1f (branches: %d, loop depth: %d, lossy transforms: %d, collision rate: %.1f%%, avg path length: %.1f, element kinds: %d)",
+		stats.Score, stats.NumBranches, stats.LoopDepth, stats.NumLossy, stats.CollisionRate*100, stats.AvgPathLen, stats.NumElemKinds)
+	return leveldata.DiagnosticList{d}
+}
+
+func schemaDiagnostic(severity leveldata.Severity, format string, args ...any) leveldata.Diagnostic {
+	return leveldata.Diagnostic{
+		Severity: severity,
+		Row:      -1,
+		Col:      -1,
+		Message:  fmt.Sprintf(format, args...),
+	}
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+	"github.com/quasilyte/gmath"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	tilesetPath := flag.String("tileset", "",
+		`path to a schemas.tsj file; the sprites and fonts are loaded relative to it`)
+	outputPath := flag.String("o", "",
+		`output file path; the format is selected by its extension (.png or .svg)`)
+	flag.Parse()
+
+	if *tilesetPath == "" {
+		log.Fatal("--tileset can't be empty")
+	}
+	if *outputPath == "" {
+		log.Fatal("-o can't be empty")
+	}
+	if len(flag.Args()) != 1 {
+		log.Fatal("expected exactly 1 positional argument (a level file)")
+	}
+
+	var render func(s *scene, assetsDir string) ([]byte, error)
+	switch strings.ToLower(filepath.Ext(*outputPath)) {
+	case ".png":
+		render = renderPNG
+	case ".svg":
+		render = renderSVG
+	default:
+		log.Fatalf("unsupported output format: %q", filepath.Ext(*outputPath))
+	}
+
+	tilesetData, err := os.ReadFile(*tilesetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tileset, err := leveldata.LoadTileset(tilesetData)
+	if err != nil {
+		log.Fatalf("[ERROR] decode tileset file: %v", err)
+	}
+
+	levelFilename := flag.Args()[0]
+	levelData, err := os.ReadFile(levelFilename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tmpl, err := leveldata.LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", levelFilename, err)
+	}
+	schema, err := leveldata.NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", levelFilename, err)
+	}
+
+	data, err := render(newScene(tileset, tmpl, schema), filepath.Dir(*tilesetPath))
+	if err != nil {
+		log.Fatalf("[ERROR] render %q: %v", levelFilename, err)
+	}
+	if err := os.WriteFile(*outputPath, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/quasilyte/gmath"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+type pngRenderer struct {
+	assetsDir string
+	images    map[string]*image.NRGBA
+}
+
+func renderPNG(s *scene, assetsDir string) ([]byte, error) {
+	r := &pngRenderer{
+		assetsDir: assetsDir,
+		images:    make(map[string]*image.NRGBA),
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, s.width, s.height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bgColor), image.Point{}, draw.Src)
+	bg, err := r.loadImage(backgroundImage)
+	if err != nil {
+		return nil, err
+	}
+	draw.Draw(dst, bg.Bounds(), bg, image.Point{}, draw.Src)
+
+	var labelFace font.Face
+	for _, e := range s.elems {
+		img, err := r.loadImage(e.sprite)
+		if err != nil {
+			return nil, err
+		}
+		drawTransformed(dst, img, e.pos, e.rotation, e.flipHorizontal)
+		if len(e.label) == 0 {
+			continue
+		}
+		if labelFace == nil {
+			labelFace, err = r.loadFace(labelFontFile, labelFontSize)
+			if err != nil {
+				return nil, err
+			}
+		}
+		topLeft := e.pos.Sub(gmath.Vec{X: s.cellWidth / 2, Y: s.cellHeight / 2})
+		drawCenteredText(dst, labelFace, defaultLCDColor, topLeft, s.cellWidth, s.cellHeight, e.label)
+	}
+
+	if len(s.stickers) != 0 {
+		sticker, err := r.loadImage(stickerImage)
+		if err != nil {
+			return nil, err
+		}
+		stickerFace, err := r.loadFace(stickerFontFile, stickerFontSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range s.stickers {
+			pt := image.Pt(int(st.pos.X), int(st.pos.Y))
+			draw.Draw(dst, sticker.Bounds().Add(pt), sticker, image.Point{}, draw.Over)
+			drawText(dst, stickerFace, stickerTextColor, st.pos.Add(stickerTextOffset), st.text)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *pngRenderer) loadImage(path string) (*image.NRGBA, error) {
+	if img, ok := r.images[path]; ok {
+		return img, nil
+	}
+	f, err := os.Open(filepath.Join(r.assetsDir, path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	decoded, err := png.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewNRGBA(decoded.Bounds())
+	draw.Draw(img, img.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+	r.images[path] = img
+	return img, nil
+}
+
+func (r *pngRenderer) loadFace(path string, size float64) (font.Face, error) {
+	data, err := os.ReadFile(filepath.Join(r.assetsDir, path))
+	if err != nil {
+		return nil, err
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// drawTransformed draws src centered at pos.
+// The image is flipped first and then rotated around its center (clockwise).
+//
+// Every destination pixel takes the nearest source pixel,
+// so the right angle rotations are pixel-exact.
+func drawTransformed(dst draw.Image, src *image.NRGBA, pos gmath.Vec, rotation gmath.Rad, flipHorizontal bool) {
+	w := float64(src.Bounds().Dx())
+	h := float64(src.Bounds().Dy())
+	sin, cos := math.Sincos(float64(rotation))
+	// Get rid of the rounding errors, like cos(pi/2)=6e-17.
+	sin = math.Round(sin*1e9) / 1e9
+	cos = math.Round(cos*1e9) / 1e9
+
+	// A bounding box of the rotated image.
+	halfW := (math.Abs(w*cos) + math.Abs(h*sin)) / 2
+	halfH := (math.Abs(w*sin) + math.Abs(h*cos)) / 2
+	bounds := image.Rect(
+		int(math.Floor(pos.X-halfW)), int(math.Floor(pos.Y-halfH)),
+		int(math.Ceil(pos.X+halfW)), int(math.Ceil(pos.Y+halfH)))
+
+	transformed := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px := float64(x) + 0.5 - pos.X
+			py := float64(y) + 0.5 - pos.Y
+			// Apply the inverse rotation to find the source pixel.
+			sx := px*cos + py*sin + w/2
+			sy := -px*sin + py*cos + h/2
+			if flipHorizontal {
+				sx = w - sx
+			}
+			if sx < 0 || sy < 0 || sx >= w || sy >= h {
+				continue
+			}
+			srcPt := src.Bounds().Min.Add(image.Pt(int(sx), int(sy)))
+			transformed.SetNRGBA(x, y, src.NRGBAAt(srcPt.X, srcPt.Y))
+		}
+	}
+	draw.Draw(dst, bounds, transformed, bounds.Min, draw.Over)
+}
+
+// drawText draws the lines starting from the top-left pos.
+func drawText(dst draw.Image, face font.Face, clr color.Color, pos gmath.Vec, lines []string) {
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(clr), Face: face}
+	metrics := face.Metrics()
+	for i, line := range lines {
+		d.Dot = fixed.Point26_6{
+			X: fixed.I(int(pos.X)),
+			Y: fixed.I(int(pos.Y)) + metrics.Ascent + metrics.Height.Mul(fixed.I(i)),
+		}
+		d.DrawString(line)
+	}
+}
+
+// drawCenteredText draws the lines in the center of the given box.
+func drawCenteredText(dst draw.Image, face font.Face, clr color.Color, pos gmath.Vec, width, height float64, lines []string) {
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(clr), Face: face}
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	top := int(pos.Y) + (int(height)-lineHeight*len(lines))/2
+	for i, line := range lines {
+		lineWidth := d.MeasureString(line).Ceil()
+		d.Dot = fixed.Point26_6{
+			X: fixed.I(int(pos.X) + (int(width)-lineWidth)/2),
+			Y: fixed.I(top+lineHeight*i) + metrics.Ascent,
+		}
+		d.DrawString(line)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+	"github.com/quasilyte/gmath"
+)
+
+// The values below mirror the game UI.
+var (
+	// defaultLCDColor is used for the configurable transform labels.
+	defaultLCDColor = color.RGBA{R: 0x2a, G: 0x95, B: 0x35, A: 0xff}
+
+	// stickerTextColor is a hint sticker label color.
+	stickerTextColor = color.NRGBA{R: 30, G: 30, B: 60, A: 220}
+
+	// bgColor is used for the areas that are not covered by schema_bg.png.
+	bgColor = color.RGBA{R: 0x19, G: 0x22, B: 0x1d, A: 0xff}
+
+	// stickerTextOffset is a label position relative to the sticker.
+	stickerTextOffset = gmath.Vec{X: 34, Y: 72}
+
+	// schemaOffset is a schema position on the game screen.
+	// Hints are not affected by it, so we need to compensate that.
+	schemaOffset = gmath.Vec{X: 96, Y: 96}
+)
+
+const (
+	labelFontSize   = 20
+	stickerFontSize = 20
+
+	labelFontFile   = "font.ttf"
+	stickerFontFile = "TidyHand.ttf"
+	stickerImage    = "hint_sticker.png"
+	backgroundImage = "schema_bg.png"
+)
+
+// scene is everything we need to draw a level.
+// All positions are in pixels, relative to the top-left grid corner.
+type scene struct {
+	width  int
+	height int
+
+	cellWidth  float64
+	cellHeight float64
+
+	elems    []sceneElem
+	stickers []sceneSticker
+}
+
+type sceneElem struct {
+	// sprite is a path relative to the assets dir.
+	sprite string
+
+	// pos is a center of the element cell.
+	pos gmath.Vec
+
+	// Transformations are applied in the same order as the game does:
+	// the sprite is flipped first, then it's rotated around its center.
+	rotation       gmath.Rad
+	flipHorizontal bool
+
+	// label is an optional text that is drawn in the center of the cell.
+	label []string
+}
+
+type sceneSticker struct {
+	// pos is a top-left sticker corner.
+	pos  gmath.Vec
+	text []string
+}
+
+func newScene(tileset *leveldata.Tileset, tmpl *leveldata.SchemaTemplate, schema *leveldata.ComponentSchema) *scene {
+	s := &scene{
+		cellWidth:  tileset.TileWidth,
+		cellHeight: tileset.TileHeight,
+	}
+	s.width = int(float64(schema.NumCols) * s.cellWidth)
+	s.height = int(float64(schema.NumRows) * s.cellHeight)
+
+	for _, e := range schema.Elems {
+		info := tileset.ElemByClass(e.TileClass)
+		elem := sceneElem{
+			sprite:   info.Sprite,
+			pos:      e.Pos,
+			rotation: e.Rotation,
+		}
+		if extra, ok := e.ExtraData.(*leveldata.AngleElemExtra); ok {
+			elem.flipHorizontal = extra.FlipHorizontally
+		}
+		if info.IsConfigurable() {
+			elem.label = strings.Split(e.ExtraData.(*leveldata.TransformElemExtra).Label(), "\n")
+		}
+		s.elems = append(s.elems, elem)
+	}
+
+	for _, h := range tmpl.Hints {
+		s.stickers = append(s.stickers, sceneSticker{
+			pos:  h.Pos.Sub(schemaOffset),
+			text: strings.Split(h.Text, "\n"),
+		})
+	}
+
+	return s
+}
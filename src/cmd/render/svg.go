@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// svgRenderer produces an SVG document that embeds all images and fonts,
+// so it can be viewed as a standalone file.
+type svgRenderer struct {
+	assetsDir string
+	buf       bytes.Buffer
+	defs      bytes.Buffer
+	images    map[string]svgImage
+	fontIDs   map[string]string
+}
+
+func renderSVG(s *scene, assetsDir string) ([]byte, error) {
+	r := &svgRenderer{
+		assetsDir: assetsDir,
+		images:    make(map[string]svgImage),
+		fontIDs:   make(map[string]string),
+	}
+
+	fmt.Fprintf(&r.buf, `<rect width="%d" height="%d" fill="%s"/>`+"\n", s.width, s.height, svgColor(bgColor))
+	bg, err := r.image(backgroundImage)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&r.buf, `<use href="#%s"/>`+"\n", bg.id)
+
+	for _, e := range s.elems {
+		img, err := r.image(e.sprite)
+		if err != nil {
+			return nil, err
+		}
+		// SVG applies the transformations from right to left.
+		degrees := math.Round(float64(e.rotation)*180/math.Pi*1e6) / 1e6
+		transform := fmt.Sprintf("translate(%g %g) rotate(%g)", e.pos.X, e.pos.Y, degrees)
+		if e.flipHorizontal {
+			transform += " scale(-1 1)"
+		}
+		transform += fmt.Sprintf(" translate(%g %g)", -float64(img.width)/2, -float64(img.height)/2)
+		fmt.Fprintf(&r.buf, `<use href="#%s" transform="%s"/>`+"\n", img.id, transform)
+
+		if len(e.label) == 0 {
+			continue
+		}
+		metrics, family, err := r.font(labelFontFile, labelFontSize)
+		if err != nil {
+			return nil, err
+		}
+		lineHeight := float64(metrics.Height.Ceil())
+		top := e.pos.Y - lineHeight*float64(len(e.label))/2
+		for i, line := range e.label {
+			y := top + lineHeight*float64(i) + fixedToFloat(metrics.Ascent)
+			r.text(e.pos.X, y, "middle", family, labelFontSize, defaultLCDColor, line)
+		}
+	}
+
+	for _, st := range s.stickers {
+		sticker, err := r.image(stickerImage)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&r.buf, `<use href="#%s" x="%g" y="%g"/>`+"\n", sticker.id, st.pos.X, st.pos.Y)
+		metrics, family, err := r.font(stickerFontFile, stickerFontSize)
+		if err != nil {
+			return nil, err
+		}
+		pos := st.pos.Add(stickerTextOffset)
+		for i, line := range st.text {
+			y := pos.Y + fixedToFloat(metrics.Ascent) + fixedToFloat(metrics.Height)*float64(i)
+			r.text(pos.X, y, "start", family, stickerFontSize, stickerTextColor, line)
+		}
+	}
+
+	var result bytes.Buffer
+	fmt.Fprintf(&result, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		s.width, s.height, s.width, s.height)
+	result.WriteString("<defs>\n")
+	result.Write(r.defs.Bytes())
+	result.WriteString("</defs>\n")
+	result.Write(r.buf.Bytes())
+	result.WriteString("</svg>\n")
+	return result.Bytes(), nil
+}
+
+func (r *svgRenderer) text(x, y float64, anchor, family string, size float64, clr color.Color, s string) {
+	fmt.Fprintf(&r.buf, `<text x="%g" y="%g" text-anchor="%s" font-family="%s" font-size="%g" fill="%s" fill-opacity="%g" xml:space="preserve">%s</text>`+"\n",
+		x, y, anchor, family, size, svgColor(clr), svgOpacity(clr), html.EscapeString(s))
+}
+
+type svgImage struct {
+	id     string
+	width  int
+	height int
+}
+
+func (r *svgRenderer) image(path string) (svgImage, error) {
+	if img, ok := r.images[path]; ok {
+		return img, nil
+	}
+	data, err := os.ReadFile(filepath.Join(r.assetsDir, path))
+	if err != nil {
+		return svgImage{}, err
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return svgImage{}, fmt.Errorf("%s: %w", path, err)
+	}
+	img := svgImage{
+		id:     fmt.Sprintf("img%d", len(r.images)),
+		width:  config.Width,
+		height: config.Height,
+	}
+	fmt.Fprintf(&r.defs, `<image id="%s" width="%d" height="%d" href="data:image/png;base64,%s"/>`+"\n",
+		img.id, img.width, img.height, base64.StdEncoding.EncodeToString(data))
+	r.images[path] = img
+	return img, nil
+}
+
+// font embeds the font file and returns its metrics and family name.
+// The metrics are used to place the text lines in the same way as the PNG renderer does.
+func (r *svgRenderer) font(path string, size float64) (font.Metrics, string, error) {
+	data, err := os.ReadFile(filepath.Join(r.assetsDir, path))
+	if err != nil {
+		return font.Metrics{}, "", err
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return font.Metrics{}, "", err
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return font.Metrics{}, "", err
+	}
+	family, ok := r.fontIDs[path]
+	if !ok {
+		family = fmt.Sprintf("font%d", len(r.fontIDs))
+		fmt.Fprintf(&r.defs, `<style>@font-face { font-family: "%s"; src: url(data:font/ttf;base64,%s); }</style>`+"\n",
+			family, base64.StdEncoding.EncodeToString(data))
+		r.fontIDs[path] = family
+	}
+	return face.Metrics(), family, nil
+}
+
+func svgColor(clr color.Color) string {
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgOpacity(clr color.Color) float64 {
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	return float64(c.A) / 255
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+	"github.com/quasilyte/gmath"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	tilesetPath := flag.String("tileset", "",
+		`path to a schemas.tsj file`)
+	maxCandidates := flag.Int64("max-candidates", leveldata.DefaultSolverMaxCandidates,
+		`max number of inputs to check per every encoded word`)
+	maxPrinted := flag.Int("max-printed", 10,
+		`max number of decodings to print per every encoded word`)
+	flag.Parse()
+
+	if *tilesetPath == "" {
+		log.Fatal("--tileset can't be empty")
+	}
+	if len(flag.Args()) == 0 {
+		log.Fatal("expected a level file and an optional list of encoded words")
+	}
+
+	tilesetData, err := os.ReadFile(*tilesetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tileset, err := leveldata.LoadTileset(tilesetData)
+	if err != nil {
+		log.Fatalf("[ERROR] decode tileset file: %v", err)
+	}
+
+	levelFilename := flag.Args()[0]
+	levelData, err := os.ReadFile(levelFilename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tmpl, err := leveldata.LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", levelFilename, err)
+	}
+	schema, err := leveldata.NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		log.Fatalf("[ERROR] %q: %v", levelFilename, err)
+	}
+
+	config := leveldata.SolverConfig{
+		MaxCandidates: *maxCandidates,
+	}
+	p := printer{maxPrinted: *maxPrinted}
+
+	hasErrors := false
+	if len(flag.Args()) > 1 {
+		// Decode the explicitly given words.
+		for _, encoded := range flag.Args()[1:] {
+			decoded, err := leveldata.Solve(schema, encoded, config)
+			if err != nil {
+				hasErrors = true
+				fmt.Fprintf(os.Stderr, "%q: %v\n", encoded, err)
+				continue
+			}
+			fmt.Printf("%s: %s\n", encoded, p.formatDecodings(decoded))
+		}
+	} else {
+		// Check that every level keyword has a unique decoding.
+		runner := leveldata.NewSchemaRunner()
+		for _, k := range schema.Keywords {
+			encoded, err := runner.Exec(schema, k)
+			if err != nil {
+				hasErrors = true
+				fmt.Fprintf(os.Stderr, "keyword %q: %v\n", k, err)
+				continue
+			}
+			decoded, err := leveldata.Solve(schema, encoded, config)
+			if err != nil {
+				if errors.Is(err, leveldata.ErrSearchSpaceTooBig) {
+					fmt.Printf("%s -> %s: [SKIP] %v\n", k, encoded, err)
+					continue
+				}
+				hasErrors = true
+				fmt.Fprintf(os.Stderr, "keyword %q: %v\n", k, err)
+				continue
+			}
+			status := "[OK]"
+			if len(decoded) != 1 {
+				status = "[AMBIGUOUS]"
+			}
+			fmt.Printf("%s -> %s: %s %s\n", k, encoded, status, p.formatDecodings(decoded))
+		}
+	}
+
+	if hasErrors {
+		os.Exit(1)
+	}
+}
+
+type printer struct {
+	maxPrinted int
+}
+
+func (p *printer) formatDecodings(decoded []string) string {
+	if len(decoded) == 0 {
+		return "no decodings"
+	}
+	s := fmt.Sprintf("%d decoding(s)", len(decoded))
+	if len(decoded) <= p.maxPrinted {
+		return s + ": " + strings.Join(decoded, " ")
+	}
+	return s + ": " + strings.Join(decoded[:p.maxPrinted], " ") + " ..."
+}
@@ -4,14 +4,13 @@ import (
 	"unicode"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/decipherism-game/leveldata"
 	"github.com/quasilyte/ge"
 	"github.com/quasilyte/ge/gesignal"
 	"github.com/quasilyte/ge/input"
 	"github.com/quasilyte/gmath"
 )
 
-const maxInputLen = 10
-
 type componentInput struct {
 	pos              gmath.Vec
 	input            *input.Handler
@@ -69,22 +68,22 @@ func (i *componentInput) Update(delta float64) {
 
 	if i.advancedOps && len(i.text) != 0 {
 		if i.cursorPos != -1 && i.input.ActionIsJustPressed(ActionCharInc) {
-			i.text[i.cursorPos] = incChar(i.text[i.cursorPos])
+			i.text[i.cursorPos] = leveldata.IncChar(i.text[i.cursorPos])
 			i.onTextChanged()
 			return
 		}
 		if i.cursorPos != -1 && i.input.ActionIsJustPressed(ActionCharDec) {
-			i.text[i.cursorPos] = decChar(i.text[i.cursorPos])
+			i.text[i.cursorPos] = leveldata.DecChar(i.text[i.cursorPos])
 			i.onTextChanged()
 			return
 		}
 		if i.input.ActionIsJustPressed(ActionRotateLeft) {
-			rotateCharsLeft(i.text)
+			leveldata.RotateCharsLeft(i.text)
 			i.onTextChanged()
 			return
 		}
 		if i.input.ActionIsJustPressed(ActionRotateRight) {
-			rotateCharsRight(i.text)
+			leveldata.RotateCharsRight(i.text)
 			i.onTextChanged()
 			return
 		}
@@ -135,7 +134,7 @@ func (i *componentInput) Update(delta float64) {
 			return
 		}
 	}
-	if len(i.text) < maxInputLen && !ebiten.IsKeyPressed(ebiten.KeyControl) {
+	if len(i.text) < leveldata.MaxInputLen && !ebiten.IsKeyPressed(ebiten.KeyControl) {
 		i.pressedRunes = ebiten.AppendInputChars(i.pressedRunes[:0])
 		if len(i.pressedRunes) != 0 {
 			changed := false
@@ -19,7 +19,11 @@ type contentStatus struct {
 	sawCollision         bool
 }
 
-func calculateContentStatus(state *gameState) contentStatus {
+// calculateContentStatus returns the unlocked game content.
+//
+// The features and notes are unlocked by the story mode progress,
+// while the chapters list is calculated for the given campaign.
+func calculateContentStatus(state *gameState, campaign *storyModeMap) contentStatus {
 	result := contentStatus{
 		solvedPolygraphic:   state.data.SolvedPolygraphic,
 		solvedShift:         state.data.SolvedShift,
@@ -36,12 +40,12 @@ func calculateContentStatus(state *gameState) contentStatus {
 	chaptersCleared := 0
 	for i := range theStoryModeMap.chapters {
 		chapter := &theStoryModeMap.chapters[i]
-		completionData := state.GetChapterCompletionData(chapter)
+		completionData := state.GetChapterCompletionData(theStoryModeMap, chapter)
 		if completionData.allLevelsCompleted && !chapter.IsBonus() {
 			chaptersCleared++
 		}
 		for _, levelName := range chapter.levels {
-			levelCompletionData := state.GetLevelCompletionData(levelName)
+			levelCompletionData := state.GetLevelCompletionData(theStoryModeMap, levelName)
 			if levelCompletionData != nil {
 				if chapter.IsBonus() {
 					result.bonusLevelsCompleted++
@@ -50,10 +54,15 @@ func calculateContentStatus(state *gameState) contentStatus {
 				}
 			}
 		}
+	}
+	result.hackedEverything = result.levelsCompleted+result.bonusLevelsCompleted == len(theStoryModeMap.levels)
+
+	for i := range campaign.chapters {
+		chapter := &campaign.chapters[i]
 		available := true
 		if chapter.requires != "" {
-			otherChapter := theStoryModeMap.getChapter(chapter.requires)
-			otherChapterStatus := state.GetChapterCompletionData(otherChapter)
+			otherChapter := campaign.getChapter(chapter.requires)
+			otherChapterStatus := state.GetChapterCompletionData(campaign, otherChapter)
 			if chapter.IsBonus() {
 				available = otherChapterStatus.secretDecoded
 			} else {
@@ -64,7 +73,6 @@ func calculateContentStatus(state *gameState) contentStatus {
 			result.chapters = append(result.chapters, chapter.name)
 		}
 	}
-	result.hackedEverything = result.levelsCompleted+result.bonusLevelsCompleted == len(theStoryModeMap.levels)
 
 	techLevel := chaptersCleared
 	result.techLevelFeatures = append(result.techLevelFeatures, "value inspector")
@@ -12,7 +12,6 @@ import (
 
 	"github.com/quasilyte/decipherism-game/leveldata"
 	"github.com/quasilyte/ge"
-	"github.com/quasilyte/ge/tiled"
 	"github.com/quasilyte/ge/ui"
 	"github.com/quasilyte/gmath"
 )
@@ -23,6 +22,7 @@ type customLevelSelectController struct {
 	scene *ge.Scene
 
 	levelSlider  gmath.Slider
+	campaigns    []*storyModeMap
 	allFilenames []string
 	levelButtons []*levelButton
 
@@ -73,7 +73,14 @@ func (c *customLevelSelectController) Init(scene *ge.Scene) {
 		}
 	}
 	c.allFilenames = allFilenames
-	c.levelSlider.SetBounds(0, len(c.allFilenames)-1)
+
+	campaigns, err := scanCampaignPacks(c.gameState.userFolder)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		l.Text = fmt.Sprintf("scan $DECIPHERISM_DATA campaigns: %v", err)
+	}
+	c.campaigns = campaigns
+
+	c.levelSlider.SetBounds(0, c.numEntries()-1)
 
 	for i := 0; i < 5; i++ {
 		b := uiRoot.NewButton(optionsButtonStyle.Resized(buttonWidth, 80))
@@ -81,12 +88,17 @@ func (c *customLevelSelectController) Init(scene *ge.Scene) {
 		buttonIndex := i
 		b.EventActivated.Connect(nil, func(b *ui.Button) {
 			fileIndex := c.levelButtons[buttonIndex].fileIndex
-			selectedFilename := c.allFilenames[fileIndex]
+			if fileIndex < len(c.campaigns) {
+				c.gameState.campaign = c.campaigns[fileIndex]
+				c.scene.Context().ChangeScene(newChapterSelectController(c.gameState))
+				return
+			}
+			selectedFilename := c.allFilenames[fileIndex-len(c.campaigns)]
 			levelData, err := os.ReadFile(selectedFilename)
 			if err != nil {
 				panic(err) // TODO: better error handling
 			}
-			levelTemplate, err := loadLevelTemplate(c.scene, levelData)
+			levelTemplate, err := loadLevelTemplate(levelData)
 			if err != nil {
 				panic(err) // Should be already verified by this moment
 			}
@@ -131,6 +143,9 @@ func (c *customLevelSelectController) Init(scene *ge.Scene) {
 	c.totalCounter.AlignHorizontal = ge.AlignHorizontalCenter
 	c.totalCounter.AlignVertical = ge.AlignVerticalCenter
 	c.totalCounter.Text = fmt.Sprintf("%d levels", len(allFilenames))
+	if len(c.campaigns) != 0 {
+		c.totalCounter.Text += fmt.Sprintf(", %d campaigns", len(c.campaigns))
+	}
 	scene.AddGraphics(c.totalCounter)
 
 	offset.Y += 128
@@ -160,22 +175,27 @@ func (c *customLevelSelectController) Update(delta float64) {
 }
 
 func (c *customLevelSelectController) leave() {
-	c.scene.Context().ChangeScene(newMainMenuController(c.gameState))
+	c.scene.Context().ChangeScene(newSimulationMenuController(c.gameState))
 }
 
 func (c *customLevelSelectController) updateSelectionPage() {
 	for i, b := range c.levelButtons {
 		b.fileIndex = c.levelSlider.Value()
 		c.levelSlider.Inc()
-		if i >= len(c.allFilenames) {
+		if i >= c.numEntries() {
 			b.node.Text = "empty"
 			b.node.SetDisabled(true)
 			continue
 		}
 		b.node.SetDisabled(false)
-		filename := c.allFilenames[b.fileIndex]
-		name := strings.TrimSuffix(filepath.Base(filename), ".json")
-		name = strings.ReplaceAll(name, "_", " ")
+		var name string
+		if b.fileIndex < len(c.campaigns) {
+			name = "[campaign] " + c.campaigns[b.fileIndex].title
+		} else {
+			filename := c.allFilenames[b.fileIndex-len(c.campaigns)]
+			name = strings.TrimSuffix(filepath.Base(filename), ".json")
+			name = strings.ReplaceAll(name, "_", " ")
+		}
 		labelText := strconv.Itoa(b.fileIndex+1) + ". " + name
 		if len(labelText) > 26 {
 			labelText = labelText[:26] + "..."
@@ -184,6 +204,12 @@ func (c *customLevelSelectController) updateSelectionPage() {
 	}
 }
 
+// numEntries reports the number of selectable items.
+// Campaigns are listed before the separate levels.
+func (c *customLevelSelectController) numEntries() int {
+	return len(c.campaigns) + len(c.allFilenames)
+}
+
 func (c *customLevelSelectController) scanCustomLevels() ([]string, error) {
 	levelsPath := filepath.Join(c.gameState.userFolder, "levels")
 
@@ -192,10 +218,6 @@ func (c *customLevelSelectController) scanCustomLevels() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	tileset, err := tiled.UnmarshalTileset(c.scene.LoadRaw(RawComponentSchemaTilesetJSON).Data)
-	if err != nil {
-		panic(err)
-	}
 	for _, f := range files {
 		if !strings.HasSuffix(f.Name(), ".json") {
 			continue
@@ -206,7 +228,7 @@ func (c *customLevelSelectController) scanCustomLevels() ([]string, error) {
 			fmt.Printf("[ERROR] load %q: %v\n", f.Name(), err)
 			continue
 		}
-		if err := leveldata.ValidateLevelData(tileset, data); err != nil {
+		if err := leveldata.ValidateLevelData(theSchemaTileset, data); err != nil {
 			fmt.Printf("[ERROR] load %q: %v\n", f.Name(), err)
 			continue
 		}
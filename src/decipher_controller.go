@@ -27,6 +27,7 @@ type decipherController struct {
 	numDecoded     int
 
 	schema       *leveldata.ComponentSchema
+	schemaView   *schemaView
 	schemaNodes  []*schemaElemNode
 	stickerNodes []*stickerNode
 	ioLogs       []string
@@ -38,8 +39,8 @@ type decipherController struct {
 	signalNode      *signalNode
 	signalNodeSpeed float64
 	simulationInput string
-	runner          *schemaRunner
-	termRunner      *schemaRunner
+	runner          *leveldata.SchemaRunner
+	termRunner      *leveldata.SchemaRunner
 
 	paused bool
 
@@ -54,13 +55,16 @@ type decipherConfig struct {
 	advancedInput    bool
 	storyMode        bool
 	levelTemplate    *leveldata.SchemaTemplate
+
+	// puzzle is set for the generated puzzle levels.
+	puzzle *generatedPuzzle
 }
 
 func newDecipherController(s *gameState, config decipherConfig) *decipherController {
 	return &decipherController{
 		gameState:  s,
-		runner:     newSchemaRunner(),
-		termRunner: newSchemaRunner(),
+		runner:     leveldata.NewSchemaRunner(),
+		termRunner: leveldata.NewSchemaRunner(),
 		config:     config,
 	}
 }
@@ -138,15 +142,19 @@ func (c *decipherController) Init(scene *ge.Scene) {
 	})
 
 	c.initComponentSchema(c.schemaBg.Pos.Offset)
+	c.schemaView = newSchemaView(c.schemaBg.Pos.Offset, 96, c.schema)
+	// The background matches the schema grid size.
+	c.schemaBg.FrameWidth = c.schemaView.Size().X
+	c.schemaBg.FrameHeight = c.schemaView.Size().Y
 
 	for _, e := range c.schema.Elems {
-		node := newSchemaElemNode(e, c.gameState.data.Options.CrtShader)
+		node := newSchemaElemNode(e, c.schemaView, c.gameState.data.Options.CrtShader)
 		scene.AddObject(node)
 		c.schemaNodes = append(c.schemaNodes, node)
 	}
 
 	for _, h := range c.config.levelTemplate.Hints {
-		hintNode := newStickerNode(h.Pos, h.Text)
+		hintNode := newStickerNode(h.Pos, c.schemaView, h.Text)
 		scene.AddObject(hintNode)
 		c.stickerNodes = append(c.stickerNodes, hintNode)
 	}
@@ -160,7 +168,11 @@ func (c *decipherController) Init(scene *ge.Scene) {
 
 	c.schema.EncodedKeywords = make([]string, len(c.keywords))
 	for i, keyword := range c.keywords {
-		c.schema.EncodedKeywords[i] = c.encodeKeyword(keyword)
+		encoded, err := c.encodeKeyword(keyword)
+		if err != nil {
+			panic(err) // Should be already verified by this moment
+		}
+		c.schema.EncodedKeywords[i] = encoded
 	}
 
 	offset := gmath.Vec{X: 1568 - 256 - 16, Y: 96*5 + 32}
@@ -199,25 +211,32 @@ func (c *decipherController) Init(scene *ge.Scene) {
 	c.terminalNode.UpdateInfo(statusInfo{})
 }
 
-func (c *decipherController) encodeKeyword(k string) string {
+func (c *decipherController) encodeKeyword(k string) (string, error) {
 	return c.termRunner.Exec(c.schema, k)
 }
 
 func (c *decipherController) clearLevel() {
+	if c.config.puzzle != nil {
+		elapsed := time.Since(c.startTime)
+		newRecord := c.gameState.data.Puzzles.onPuzzleSolved(c.config.puzzle, elapsed)
+		c.scene.Context().SaveGameData("save", *c.gameState.data)
+		c.scene.Context().ChangeScene(newPuzzleResultsController(c.gameState, *c.config.puzzle, elapsed, newRecord))
+		return
+	}
 	if !c.config.storyMode {
 		c.scene.Context().ChangeScene(newMainMenuController(c.gameState))
 		return
 	}
 
-	completionData := xslices.Find(c.gameState.data.CompletedLevels, func(d *completedLevelData) bool {
-		return d.Name == c.gameState.level.name
-	})
+	completionData := c.gameState.GetLevelCompletionData(c.gameState.campaign, c.gameState.level.name)
 	if completionData == nil {
-		c.gameState.data.CompletedLevels = append(c.gameState.data.CompletedLevels, completedLevelData{
+		c.gameState.AddCompletedLevel(c.gameState.campaign, completedLevelData{
 			Name:          c.gameState.level.name,
 			SecretKeyword: c.secretDecoded,
 		})
-		c.gameState.data.CompletionTime += time.Since(c.startTime)
+		if c.gameState.campaign == theStoryModeMap {
+			c.gameState.data.CompletionTime += time.Since(c.startTime)
+		}
 
 	} else if c.secretDecoded {
 		completionData.SecretKeyword = true
@@ -255,9 +274,7 @@ func (c *decipherController) onProgramCompleted(output string) {
 	if c.simulationInput == c.config.secretKeyword {
 		c.scene.Context().Audio.PlaySound(AudioSecretUnlocked)
 		c.secretDecoded = true
-		completionData := xslices.Find(c.gameState.data.CompletedLevels, func(d *completedLevelData) bool {
-			return d.Name == c.gameState.level.name
-		})
+		completionData := c.gameState.GetLevelCompletionData(c.gameState.campaign, c.gameState.level.name)
 		if completionData != nil {
 			completionData.SecretKeyword = true
 			c.outputLabel.SetColor(successLCDColor)
@@ -287,10 +304,24 @@ func (c *decipherController) onProgramCompleted(output string) {
 	}
 }
 
+func (c *decipherController) onProgramLooped() {
+	if c.signalNode != nil {
+		c.signalNode.Dispose()
+		c.signalNode = nil
+	}
+	c.outputLabel.text = "?"
+	c.statusLabel.text = "LOOPING"
+	c.outputLabel.SetColor(collisionLCDColor)
+}
+
 func (c *decipherController) prepareNextStep(sig *signalNode) {
-	dst, hasMore := c.runner.RunStep()
+	dst, hasMore, err := c.runner.RunStepChecked()
+	if err != nil {
+		c.onProgramLooped()
+		return
+	}
 	if !hasMore {
-		c.onProgramCompleted(string(c.runner.data))
+		c.onProgramCompleted(string(c.runner.Data()))
 		return
 	}
 	sig.dst = dst
@@ -299,7 +330,9 @@ func (c *decipherController) prepareNextStep(sig *signalNode) {
 func (c *decipherController) nextStep(sig *signalNode) {
 	var clr ge.ColorScale
 	clr.SetRGBA(0xd1, 0xc2, 0x73, 255)
-	c.scene.AddObject(newPingEffectNode(sig.pos, clr))
+	if c.schemaView.IsVisible(sig.pos) {
+		c.scene.AddObject(newPingEffectNode(sig.pos.Add(c.schemaView.Offset()), clr))
+	}
 	if c.paused {
 		return
 	}
@@ -323,17 +356,18 @@ func (c *decipherController) isInTerminalMode() bool {
 func (c *decipherController) makeStatusInfo() statusInfo {
 	value := "?"
 	if c.signalNode != nil {
-		value = string(c.runner.data)
+		value = string(c.runner.Data())
 	}
 
-	var predictedOutput string
+	predictedOutput := "?"
 	if len(c.componentInput.text) != 0 {
-		b := []byte(c.encodeKeyword(string(c.componentInput.text)))
-		maskedLetter := fnvhash(b) % uint64(len(b))
-		b[maskedLetter] = '?'
-		predictedOutput = string(b)
-	} else {
-		predictedOutput = "?"
+		encoded, err := c.encodeKeyword(string(c.componentInput.text))
+		if err == nil && len(encoded) != 0 {
+			b := []byte(encoded)
+			maskedLetter := leveldata.FNVHash(b) % uint64(len(b))
+			b[maskedLetter] = '?'
+			predictedOutput = string(b)
+		}
 	}
 
 	return statusInfo{
@@ -383,7 +417,13 @@ func (c *decipherController) swapMode() {
 
 func (c *decipherController) leave() {
 	c.scene.Audio().PauseCurrentMusic()
-	if c.config.storyMode {
+	if c.config.puzzle != nil {
+		if c.config.puzzle.mode == puzzleEndless {
+			c.gameState.data.Puzzles.EndlessStreak = 0
+			c.scene.Context().SaveGameData("save", *c.gameState.data)
+		}
+		c.scene.Context().ChangeScene(newSimulationMenuController(c.gameState))
+	} else if c.config.storyMode {
 		c.scene.Context().ChangeScene(newLevelSelectController(c.gameState))
 	} else {
 		c.scene.Context().ChangeScene(newMainMenuController(c.gameState))
@@ -420,6 +460,12 @@ func (c *decipherController) Update(delta float64) {
 		return
 	}
 
+	if !c.isInTerminalMode() && c.schemaView.IsScrollable() {
+		if c.handleScroll() {
+			return
+		}
+	}
+
 	if (c.paused || c.signalNode == nil) && c.gameState.input.ActionIsJustPressed(ActionModeSwap) {
 		c.swapMode()
 		return
@@ -452,7 +498,12 @@ func (c *decipherController) Update(delta float64) {
 		if c.gameState.input.ActionIsJustPressed(ActionInstantRunProgram) {
 			c.gameState.data.UsedHiddenKeybinds = true
 			c.simulationInput = string(c.componentInput.text)
-			c.onProgramCompleted(c.encodeKeyword(c.simulationInput))
+			output, err := c.encodeKeyword(c.simulationInput)
+			if err != nil {
+				c.onProgramLooped()
+				return
+			}
+			c.onProgramCompleted(output)
 			return
 		}
 		if c.gameState.input.ActionIsJustPressed(ActionStartProgram) {
@@ -465,7 +516,7 @@ func (c *decipherController) Update(delta float64) {
 			c.outputLabel.SetColor(defaultLCDColor)
 			c.simulationInput = string(c.componentInput.text)
 			c.runner.Reset(c.schema, c.componentInput.text)
-			c.signalNode = newSignalNode(c.schema.Entry.Pos)
+			c.signalNode = newSignalNode(c.schema.Entry.Pos, c.schemaView)
 			c.signalNode.speed = c.signalNodeSpeed
 			c.prepareNextStep(c.signalNode)
 			c.signalNode.EventDestinationReached.Connect(nil, c.nextStep)
@@ -475,6 +526,24 @@ func (c *decipherController) Update(delta float64) {
 	}
 }
 
+func (c *decipherController) handleScroll() bool {
+	var delta gmath.Vec
+	switch {
+	case c.gameState.input.ActionIsJustPressed(ActionScrollLeft):
+		delta.X = -96
+	case c.gameState.input.ActionIsJustPressed(ActionScrollRight):
+		delta.X = 96
+	case c.gameState.input.ActionIsJustPressed(ActionScrollUp):
+		delta.Y = -96
+	case c.gameState.input.ActionIsJustPressed(ActionScrollDown):
+		delta.Y = 96
+	default:
+		return false
+	}
+	c.schemaView.Scroll(delta)
+	return true
+}
+
 func (c *decipherController) initComponentSchema(offset gmath.Vec) {
 	schema, err := leveldata.NewSchemaBuilder(offset, c.config.levelTemplate).Build()
 	if err != nil {
@@ -3,7 +3,6 @@ package main
 import (
 	"time"
 
-	resource "github.com/quasilyte/ebitengine-resource"
 	"github.com/quasilyte/ge/input"
 	"github.com/quasilyte/ge/xslices"
 	"github.com/quasilyte/gmath"
@@ -11,6 +10,7 @@ import (
 
 type gameState struct {
 	input      *input.Handler
+	campaign   *storyModeMap
 	chapter    *storyModeChapter
 	level      storyModeLevel
 	data       *persistentGameData
@@ -25,20 +25,51 @@ type chapterCompletionData struct {
 	secretDecoded      bool
 }
 
-func (state *gameState) GetLevelCompletionData(name string) *completedLevelData {
-	return xslices.Find(state.data.CompletedLevels, func(l *completedLevelData) bool {
+// CompletedLevels returns the campaign progress data.
+// The story mode progress is stored separately from the custom campaigns.
+// A custom campaign without any completed levels has no progress data.
+func (state *gameState) CompletedLevels(m *storyModeMap) []completedLevelData {
+	if levels := state.findCompletedLevels(m); levels != nil {
+		return *levels
+	}
+	return nil
+}
+
+// AddCompletedLevel records a level completion.
+// The custom campaign progress data is created on its first completed level.
+func (state *gameState) AddCompletedLevel(m *storyModeMap, level completedLevelData) {
+	levels := state.findCompletedLevels(m)
+	if levels == nil {
+		state.data.Campaigns = append(state.data.Campaigns, campaignProgressData{Name: m.name})
+		levels = &state.data.Campaigns[len(state.data.Campaigns)-1].CompletedLevels
+	}
+	*levels = append(*levels, level)
+}
+
+func (state *gameState) findCompletedLevels(m *storyModeMap) *[]completedLevelData {
+	if m == theStoryModeMap {
+		return &state.data.CompletedLevels
+	}
+	for i := range state.data.Campaigns {
+		if state.data.Campaigns[i].Name == m.name {
+			return &state.data.Campaigns[i].CompletedLevels
+		}
+	}
+	return nil
+}
+
+func (state *gameState) GetLevelCompletionData(m *storyModeMap, name string) *completedLevelData {
+	return xslices.Find(state.CompletedLevels(m), func(l *completedLevelData) bool {
 		return l.Name == name
 	})
 }
 
-func (state *gameState) GetChapterCompletionData(c *storyModeChapter) chapterCompletionData {
+func (state *gameState) GetChapterCompletionData(m *storyModeMap, c *storyModeChapter) chapterCompletionData {
 	var result chapterCompletionData
 	levelsCompleted := 0
 	keywordsSolved := 0
 	for i, levelName := range c.levels {
-		levelData := xslices.Find(state.data.CompletedLevels, func(l *completedLevelData) bool {
-			return l.Name == levelName
-		})
+		levelData := state.GetLevelCompletionData(m, levelName)
 		if levelData != nil {
 			levelsCompleted++
 			if levelData.SecretKeyword {
@@ -60,6 +91,7 @@ func (state *gameState) GetChapterCompletionData(c *storyModeChapter) chapterCom
 
 type persistentGameData struct {
 	CompletedLevels     []completedLevelData
+	Campaigns           []campaignProgressData
 	SolvedAtbash        bool
 	SolvedRot13         bool
 	SolvedIncDec        bool
@@ -71,6 +103,7 @@ type persistentGameData struct {
 	UsedHiddenKeybinds  bool
 	SawCollision        bool
 	CompletionTime      time.Duration
+	Puzzles             puzzleProgressData
 	Options             gameOptions
 }
 
@@ -85,7 +118,31 @@ type completedLevelData struct {
 	SecretKeyword bool
 }
 
+// puzzleProgressData is a generated puzzles save data.
+type puzzleProgressData struct {
+	// LastDailyDate is the last solved daily puzzle date.
+	LastDailyDate   string
+	DailyStreak     int
+	BestDailyStreak int
+	BestDailyTime   time.Duration
+
+	// EndlessStreak is reset when a player leaves the endless mode puzzle.
+	EndlessStreak     int
+	BestEndlessStreak int
+	// BestEndlessTimes are indexed by the puzzle difficulty minus 1.
+	BestEndlessTimes []time.Duration
+}
+
+// campaignProgressData is a custom campaign save data.
+type campaignProgressData struct {
+	Name            string
+	CompletedLevels []completedLevelData
+}
+
 type storyModeMap struct {
+	name  string
+	title string
+
 	chapters []storyModeChapter
 	levels   map[string]storyModeLevel
 }
@@ -109,150 +166,9 @@ func (c *storyModeChapter) IsBonus() bool { return c.keyword == "" }
 
 type storyModeLevel struct {
 	name string
-	id   resource.RawID
+	data []byte
 }
 
-var theStoryModeMap = &storyModeMap{
-	chapters: []storyModeChapter{
-		{
-			label:    "1+",
-			name:     "bonus1",
-			requires: "story1",
-			levels: []string{
-				"double_negation",
-				"spellbook",
-				"lossy_conversion",
-			},
-			gridPos: gmath.Vec{X: 0, Y: 1},
-		},
-		{
-			label:    "2+",
-			name:     "bonus2",
-			requires: "story2",
-			levels: []string{
-				"polygraphic_atbash",
-				"sub_loop",
-				"branchless_encoder",
-			},
-			gridPos: gmath.Vec{X: 1, Y: 1},
-		},
-		{
-			label:    "3+",
-			name:     "bonus3",
-			requires: "story3",
-			levels: []string{
-				"symmetry",
-				"double_zigzag",
-				"deduction",
-			},
-			gridPos: gmath.Vec{X: 2, Y: 1},
-		},
-		{
-			label:    "4+",
-			name:     "bonus4",
-			requires: "story4",
-			levels: []string{
-				"clear_head",
-				"even_odd_add",
-				"rumble",
-			},
-			gridPos: gmath.Vec{X: 3, Y: 1},
-		},
-		{
-			label:    "5+",
-			name:     "bonus5",
-			requires: "story5",
-			levels: []string{
-				"claws",
-				"stuttering",
-				"the_best_number",
-			},
-			gridPos: gmath.Vec{X: 4, Y: 1},
-		},
-		{
-			label:    "6+",
-			name:     "bonus6",
-			requires: "story6",
-			levels: []string{
-				"conveyor",
-				"pyramid",
-				"mission_impossible",
-			},
-			gridPos: gmath.Vec{X: 3, Y: 2},
-		},
-
-		{
-			label:   "1",
-			name:    "story1",
-			keyword: "rain",
-			levels: []string{
-				"hello_world",
-				"rinse_repeat",
-				"add_or_sub",
-			},
-			gridPos: gmath.Vec{X: 0, Y: 0},
-		},
-		{
-			label:    "2",
-			name:     "story2",
-			requires: "story1",
-			keyword:  "storm",
-			levels: []string{
-				"vowel_shifter",
-				"efforts_negated",
-				"addsub_negation",
-			},
-			gridPos: gmath.Vec{X: 1, Y: 0},
-		},
-		{
-			label:    "3",
-			name:     "story3",
-			requires: "story2",
-			keyword:  "thunder",
-			levels: []string{
-				"atbash",
-				"swap_shifter",
-				"determination",
-			},
-			gridPos: gmath.Vec{X: 2, Y: 0},
-		},
-		{
-			label:    "4",
-			name:     "story4",
-			requires: "story3",
-			keyword:  "tsunami",
-			levels: []string{
-				"ladder",
-				"red_herring",
-				"binary_tree",
-			},
-			gridPos: gmath.Vec{X: 3, Y: 0},
-		},
-		{
-			label:    "5",
-			name:     "story5",
-			requires: "story4",
-			keyword:  "whirlwind",
-			levels: []string{
-				"switch",
-				"dotmask",
-				"odd_evening",
-				"nop_shuffling",
-			},
-			gridPos: gmath.Vec{X: 4, Y: 0},
-		},
-		{
-			label:    "6",
-			name:     "story6",
-			requires: "story5",
-			keyword:  "cloudburst",
-			levels: []string{
-				"single_key",
-				"rot13",
-				"fixed_cond",
-				"spiral",
-			},
-			gridPos: gmath.Vec{X: 4, Y: 2},
-		},
-	},
-}
+// theStoryModeMap is a built-in campaign.
+// It's loaded from the embedded levels/campaign.json manifest.
+var theStoryModeMap *storyModeMap
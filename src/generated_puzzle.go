@@ -0,0 +1,146 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/quasilyte/decipherism-game/leveldata"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/gmath"
+)
+
+type puzzleMode int
+
+const (
+	puzzleEndless puzzleMode = iota
+	puzzleDaily
+)
+
+// dailyPuzzleDifficulty is the same for every day,
+// so the best daily time is comparable.
+const dailyPuzzleDifficulty = 3
+
+// endlessPuzzlesPerDifficulty is the number of puzzles
+// to solve in a row to get to the next endless mode difficulty.
+const endlessPuzzlesPerDifficulty = 3
+
+const dailyPuzzleDateLayout = "2006-01-02"
+
+// generatedPuzzle describes a level that is created by leveldata.GenerateLevel.
+type generatedPuzzle struct {
+	mode       puzzleMode
+	seed       int64
+	difficulty int
+
+	// date is a daily puzzle UTC date, see dailyPuzzleDateLayout.
+	date string
+}
+
+// newDailyPuzzle returns the puzzle of the day.
+// Everyone gets the same daily puzzle as long as the word list is the same.
+func newDailyPuzzle(now time.Time) generatedPuzzle {
+	return generatedPuzzle{
+		mode:       puzzleDaily,
+		seed:       leveldata.DailyGeneratorSeed(now),
+		difficulty: dailyPuzzleDifficulty,
+		date:       now.UTC().Format(dailyPuzzleDateLayout),
+	}
+}
+
+// newEndlessPuzzle returns a random puzzle.
+// The difficulty grows along with the endless mode streak.
+func newEndlessPuzzle(data *puzzleProgressData, rand *gmath.Rand) generatedPuzzle {
+	difficulty := 1 + data.EndlessStreak/endlessPuzzlesPerDifficulty
+	return generatedPuzzle{
+		mode:       puzzleEndless,
+		seed:       int64(rand.IntRange(1, math.MaxInt32)),
+		difficulty: gmath.ClampMax(difficulty, leveldata.MaxGeneratorDifficulty),
+	}
+}
+
+// startPuzzle generates the puzzle level and opens it.
+// The terminal upgrades are unlocked by the story mode progress.
+func startPuzzle(scene *ge.Scene, state *gameState, puzzle generatedPuzzle) {
+	levelTemplate, err := leveldata.GenerateLevel(theSchemaTileset, leveldata.GeneratorConfig{
+		Seed:       puzzle.seed,
+		Difficulty: puzzle.difficulty,
+		Words:      theGeneratorWords,
+	})
+	if err != nil {
+		panic(err) // The bundled word list is checked by the leveldata tests
+	}
+	config := decipherConfig{
+		levelTemplate: levelTemplate,
+		puzzle:        &puzzle,
+	}
+	initDecipherConfig(calculateContentStatus(state, theStoryModeMap), &config)
+	scene.Context().ChangeScene(newDecipherController(state, config))
+}
+
+// currentDailyStreak returns 0 if the daily streak is already broken.
+func (data *puzzleProgressData) currentDailyStreak(today string) int {
+	if data.LastDailyDate == today || data.LastDailyDate == previousDailyDate(today) {
+		return data.DailyStreak
+	}
+	return 0
+}
+
+// onPuzzleSolved updates the streaks and the best times.
+// It reports whether the elapsed time is a new record.
+// Only the first solve of a daily puzzle is recorded.
+func (data *puzzleProgressData) onPuzzleSolved(puzzle *generatedPuzzle, elapsed time.Duration) bool {
+	switch puzzle.mode {
+	case puzzleDaily:
+		// Solving the same daily puzzle again doesn't count:
+		// a replay of an already known puzzle would be an unfair record.
+		if data.LastDailyDate == puzzle.date {
+			return false
+		}
+		if data.LastDailyDate == previousDailyDate(puzzle.date) {
+			data.DailyStreak++
+		} else {
+			data.DailyStreak = 1
+		}
+		data.LastDailyDate = puzzle.date
+		data.BestDailyStreak = gmath.ClampMin(data.BestDailyStreak, data.DailyStreak)
+		return updateBestTime(&data.BestDailyTime, elapsed)
+
+	default:
+		data.EndlessStreak++
+		data.BestEndlessStreak = gmath.ClampMin(data.BestEndlessStreak, data.EndlessStreak)
+		for len(data.BestEndlessTimes) < puzzle.difficulty {
+			data.BestEndlessTimes = append(data.BestEndlessTimes, 0)
+		}
+		return updateBestTime(&data.BestEndlessTimes[puzzle.difficulty-1], elapsed)
+	}
+}
+
+func (data *puzzleProgressData) bestTime(puzzle *generatedPuzzle) time.Duration {
+	if puzzle.mode == puzzleDaily {
+		return data.BestDailyTime
+	}
+	if puzzle.difficulty > len(data.BestEndlessTimes) {
+		return 0
+	}
+	return data.BestEndlessTimes[puzzle.difficulty-1]
+}
+
+func updateBestTime(best *time.Duration, elapsed time.Duration) bool {
+	if *best == 0 || elapsed < *best {
+		*best = elapsed
+		return true
+	}
+	return false
+}
+
+func previousDailyDate(date string) string {
+	t, err := time.Parse(dailyPuzzleDateLayout, date)
+	if err != nil {
+		return ""
+	}
+	return t.AddDate(0, 0, -1).Format(dailyPuzzleDateLayout)
+}
+
+func formatPuzzleTime(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
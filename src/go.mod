@@ -6,6 +6,7 @@ require (
 	github.com/hajimehoshi/ebiten/v2 v2.4.16
 	github.com/quasilyte/ge v0.0.0-20230202151823-9fbdf6e7770e
 	github.com/quasilyte/gmath v0.0.0-20221217210116-fba37a2e15c7
+	golang.org/x/image v0.3.0
 )
 
 require (
@@ -19,7 +20,6 @@ require (
 	github.com/quasilyte/ebitengine-resource v0.5.1-0.20230131121810-c18be064e3ae // indirect
 	golang.org/x/exp v0.0.0-20221023144134-a1e5550cf13e // indirect
 	golang.org/x/exp/shiny v0.0.0-20230118134722-a68e582fa157 // indirect
-	golang.org/x/image v0.3.0 // indirect
 	golang.org/x/mobile v0.0.0-20221110043201-43a038452099 // indirect
 	golang.org/x/sys v0.4.0 // indirect
 	golang.org/x/text v0.6.0 // indirect
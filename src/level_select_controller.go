@@ -6,7 +6,6 @@ import (
 
 	"github.com/quasilyte/decipherism-game/leveldata"
 	"github.com/quasilyte/ge"
-	"github.com/quasilyte/ge/tiled"
 	"github.com/quasilyte/ge/ui"
 	"github.com/quasilyte/ge/xslices"
 	"github.com/quasilyte/gmath"
@@ -36,6 +35,7 @@ func (c *levelSelectController) Init(scene *ge.Scene) {
 	layer := ge.NewShaderLayer()
 	layer.Shader = scene.NewShader(ShaderHandwriting)
 
+	campaign := c.gameState.campaign
 	chapter := c.gameState.chapter
 	levelStrings := make([]string, len(chapter.levels))
 	for i := range c.gameState.chapter.levels {
@@ -49,22 +49,21 @@ func (c *levelSelectController) Init(scene *ge.Scene) {
 	var encodedKeyword string
 	c.secretKeywords = make([]string, len(chapter.levels))
 	if !chapter.IsBonus() {
-		tileset, err := tiled.UnmarshalTileset(scene.LoadRaw(RawComponentSchemaTilesetJSON).Data)
-		if err != nil {
-			panic(err)
-		}
-		runner := newSchemaRunner()
+		runner := leveldata.NewSchemaRunner()
 		inputData := []byte(chapter.keyword)
 		for i, levelName := range chapter.levels {
-			level := theStoryModeMap.levels[levelName]
-			levelData := scene.LoadRaw(level.id).Data
-			schema := leveldata.DecodeSchema(gmath.Vec{}, tileset, levelData)
-			completionData := c.gameState.GetLevelCompletionData(levelName)
+			level := campaign.levels[levelName]
+			schema := leveldata.DecodeSchema(gmath.Vec{}, theSchemaTileset, level.data)
+			completionData := c.gameState.GetLevelCompletionData(campaign, levelName)
 			if completionData != nil && completionData.SecretKeyword {
 				levelStrings[i] += "  (" + strings.ToUpper(string(inputData)) + ")"
 			}
 			c.secretKeywords[i] = string(inputData) // A non-encoded input
-			inputData = []byte(runner.Exec(schema, string(inputData)))
+			encoded, err := runner.Exec(schema, string(inputData))
+			if err != nil {
+				panic(err) // Builtin level should never contain any errors
+			}
+			inputData = []byte(encoded)
 		}
 		encodedKeyword = strings.ToUpper(string(inputData))
 	}
@@ -84,7 +83,7 @@ func (c *levelSelectController) Init(scene *ge.Scene) {
 	scene.AddGraphics(layer)
 }
 
-func (c *levelSelectController) initDecipherConfig(content contentStatus, config *decipherConfig) {
+func initDecipherConfig(content contentStatus, config *decipherConfig) {
 	config.terminalUpgrades.valueInspector = xslices.Contains(content.techLevelFeatures, "value inspector")
 	config.terminalUpgrades.textBuffer = xslices.Contains(content.techLevelFeatures, "text buffer")
 	config.terminalUpgrades.branchingInfo = xslices.Contains(content.techLevelFeatures, "branching info")
@@ -103,29 +102,30 @@ func (c *levelSelectController) initUI(offset gmath.Vec) {
 	offset = offset.Add(gmath.Vec{X: 192, Y: 156})
 
 	var bgroup buttonGroup
+	campaign := c.gameState.campaign
 	chapter := c.gameState.chapter
 	for i, levelName := range chapter.levels {
-		level := theStoryModeMap.levels[levelName]
+		level := campaign.levels[levelName]
 		secretKeyword := c.secretKeywords[i]
 		b := uiRoot.NewButton(outlineButtonStyle.Resized(454, 80))
 		bgroup.AddButton(b)
 		b.EventActivated.Connect(nil, func(_ *ui.Button) {
-			content := calculateContentStatus(c.gameState)
+			content := calculateContentStatus(c.gameState, campaign)
 			c.gameState.level = level
 			c.gameState.content = content
 			config := decipherConfig{
 				secretKeyword: secretKeyword,
 				storyMode:     true,
 			}
-			c.initDecipherConfig(content, &config)
-			levelTemplate, err := loadLevelTemplate(c.scene, c.scene.LoadRaw(c.gameState.level.id).Data)
+			initDecipherConfig(content, &config)
+			levelTemplate, err := loadLevelTemplate(level.data)
 			if err != nil {
 				panic(err) // Builtin level should never contain any errors
 			}
 			config.levelTemplate = levelTemplate
 			c.scene.Context().ChangeScene(newDecipherController(c.gameState, config))
 		})
-		completionData := c.gameState.GetLevelCompletionData(levelName)
+		completionData := c.gameState.GetLevelCompletionData(campaign, levelName)
 		if completionData != nil {
 			checkmark := c.scene.NewSprite(ImageCompleteMark)
 			checkmark.Pos.Offset = offset.Add(gmath.Vec{X: -154, Y: 40})
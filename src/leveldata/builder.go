@@ -2,22 +2,30 @@ package leveldata
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
-	"github.com/quasilyte/ge/tiled"
 	"github.com/quasilyte/gmath"
 )
 
 const (
-	NumSchemaCols int = 12
-	NumSchemaRows int = 8
+	// DefaultNumSchemaCols and DefaultNumSchemaRows describe
+	// the grid size that fits the game screen without scrolling.
+	DefaultNumSchemaCols int = 12
+	DefaultNumSchemaRows int = 8
+
+	MaxNumSchemaCols int = 64
+	MaxNumSchemaRows int = 64
 )
 
 type SchemaBuilder struct {
 	template    *SchemaTemplate
 	schema      *ComponentSchema
 	offset      gmath.Vec
-	elemByIndex [NumSchemaCols * NumSchemaRows]*SchemaElem
+	numCols     int
+	numRows     int
+	elemByIndex []*SchemaElem
+	diagnostics DiagnosticList
 }
 
 type elemShape struct {
@@ -38,12 +46,8 @@ func (shape *elemShape) CanConnectTo(otherShape *elemShape) bool {
 	return false
 }
 
-func DecodeSchema(offset gmath.Vec, tileset *tiled.Tileset, data []byte) *ComponentSchema {
-	m, err := tiled.UnmarshalMap(data)
-	if err != nil {
-		panic(err)
-	}
-	t, err := TilemapToTemplate(tileset, m)
+func DecodeSchema(offset gmath.Vec, tileset *Tileset, data []byte) *ComponentSchema {
+	t, err := LoadLevelTemplate(tileset, data)
 	if err != nil {
 		panic(err) // Used only for builtin levels, should never panic
 	}
@@ -62,22 +66,15 @@ func NewSchemaBuilder(offset gmath.Vec, t *SchemaTemplate) *SchemaBuilder {
 	}
 }
 
-func (b *SchemaBuilder) Build() (result *ComponentSchema, err error) {
-	defer func() {
-		rv := recover()
-		if rv == nil {
-			return
-		}
-		e, ok := rv.(error)
-		if ok {
-			err = e
-			return
-		}
-		panic(rv)
-	}()
-
+// Build creates a component schema from the template.
+//
+// All problems found during the build are reported as a DiagnosticList error.
+func (b *SchemaBuilder) Build() (*ComponentSchema, error) {
 	b.build()
-	return b.schema, err
+	if len(b.diagnostics) != 0 {
+		return nil, b.diagnostics
+	}
+	return b.schema, nil
 }
 
 func (b *SchemaBuilder) elemShape(e *SchemaElem) elemShape {
@@ -85,14 +82,16 @@ func (b *SchemaBuilder) elemShape(e *SchemaElem) elemShape {
 }
 
 func (b *SchemaBuilder) rowcolByPos(pos gmath.Vec) (int, int) {
-	col := int(pos.X-b.offset.X) / int(b.template.Tileset.TileWidth)
-	row := int(pos.Y-b.offset.Y) / int(b.template.Tileset.TileHeight)
+	col := int(math.Floor((pos.X - b.offset.X) / b.template.Tileset.TileWidth))
+	row := int(math.Floor((pos.Y - b.offset.Y) / b.template.Tileset.TileHeight))
 	return row, col
 }
 
-func (b *SchemaBuilder) indexByPos(pos gmath.Vec) int {
-	row, col := b.rowcolByPos(pos)
-	return row*NumSchemaCols + col
+func (b *SchemaBuilder) cellCenter(row, col int) gmath.Vec {
+	return gmath.Vec{
+		X: b.offset.X + (float64(col)+0.5)*b.template.Tileset.TileWidth,
+		Y: b.offset.Y + (float64(row)+0.5)*b.template.Tileset.TileHeight,
+	}
 }
 
 func (b *SchemaBuilder) visitNeighbours(elem *SchemaElem, f func(*SchemaElem)) {
@@ -106,10 +105,10 @@ func (b *SchemaBuilder) visitNeighbours(elem *SchemaElem, f func(*SchemaElem)) {
 	for _, rowcol := range toVisit {
 		row := rowcol[0]
 		col := rowcol[1]
-		if (row < 0 || row >= NumSchemaRows) || (col < 0 || col >= NumSchemaCols) {
+		if (row < 0 || row >= b.numRows) || (col < 0 || col >= b.numCols) {
 			continue
 		}
-		if e := b.elemByIndex[row*NumSchemaCols+col]; e != nil {
+		if e := b.elemByIndex[row*b.numCols+col]; e != nil {
 			f(e)
 		} else {
 		}
@@ -172,16 +171,35 @@ func (b *SchemaBuilder) build() {
 	s.NumKeywords = b.template.NumKeywords
 	s.Keywords = b.template.Keywords
 
+	b.numCols = b.template.NumCols
+	b.numRows = b.template.NumRows
+	if b.numCols == 0 && b.numRows == 0 {
+		b.numCols = DefaultNumSchemaCols
+		b.numRows = DefaultNumSchemaRows
+	}
+	if b.numCols < 1 || b.numCols > MaxNumSchemaCols || b.numRows < 1 || b.numRows > MaxNumSchemaRows {
+		b.schemaErrorf("invalid grid size %dx%d: the max size is %dx%d",
+			b.numCols, b.numRows, MaxNumSchemaCols, MaxNumSchemaRows)
+		return
+	}
+	s.NumCols = b.numCols
+	s.NumRows = b.numRows
+	b.elemByIndex = make([]*SchemaElem, b.numCols*b.numRows)
+
 	numInputs := 0
 	foundOutput := false
 	elemList := make([]*SchemaElem, 0, 24)
 
 	for _, t := range b.template.Elems {
+		info := b.template.Tileset.ElemByClass(t.Class)
+		elemKind := UnknownElem
 		tileClassID := t.ClassID
-		if tileClassID == -1 {
-			tileClassID = b.template.Tileset.TileByClass(t.Class).Index
+		if info != nil {
+			elemKind = info.Kind
+			if tileClassID == -1 {
+				tileClassID = info.TileID
+			}
 		}
-		elemKind := getSchemaElemKind(t.Class)
 		elem := &SchemaElem{
 			Pos:         t.Pos.Add(b.offset),
 			TileClassID: tileClassID,
@@ -190,28 +208,49 @@ func (b *SchemaBuilder) build() {
 			Rotation:    t.Rotation,
 			ExtraData:   t.ExtraData,
 		}
+		elem.Row, elem.Col = b.rowcolByPos(elem.Pos)
 		if elemKind == UnknownElem {
 			b.errorf(elem, "unexpected elem class: %s", t.Class)
+			continue
 		}
-		// TODO: use a tileset metadata for that.
-		switch {
-		case strings.HasSuffix(elem.TileClass, "_dotted") || strings.HasSuffix(elem.TileClass, "_undotted") || strings.HasSuffix(elem.TileClass, "_even") || strings.HasSuffix(elem.TileClass, "_odd"):
-			s.HasCondTransform = true
-		case strings.Contains(elem.TileClass, "polygraphic"):
-			s.HasPolygraphic = true
-		case strings.Contains(elem.TileClass, "atbash"):
-			s.HasAtbash = true
-		case strings.Contains(elem.TileClass, "rot13"):
-			s.HasRot13 = true
-		case strings.Contains(elem.TileClass, "add") || strings.Contains(elem.TileClass, "sub"):
-			s.HasIncDec = true
-		case strings.Contains(elem.TileClass, "rotate") || strings.Contains(elem.TileClass, "reverse"):
-			s.HasShift = true
-		case strings.Contains(elem.TileClass, "ifnot") || strings.Contains(elem.TileClass, "inv_repeater"):
-			s.HasNegation = true
+		if elemKind == TransformElem && elem.ExtraData == nil {
+			if info.IsConfigurable() {
+				b.errorf(elem, "transform op is not set")
+			}
+			elem.ExtraData = info.Transform
+		}
+		if extra, ok := elem.ExtraData.(*IfElemExtra); ok {
+			if err := validateIfElemExtra(extra); err != nil {
+				b.errorf(elem, "%v", err)
+			}
+		}
+		tags := info.Tags
+		if info.IsConfigurable() {
+			tags = nil
+			if extra, ok := elem.ExtraData.(*TransformElemExtra); ok && extra != nil {
+				tags = b.template.Tileset.transformTags(extra)
+			}
+		}
+		for _, tag := range tags {
+			s.addFeature(tag)
+		}
+		row, col := elem.Row, elem.Col
+		if (row < 0 || row >= b.numRows) || (col < 0 || col >= b.numCols) {
+			b.errorf(elem, "element at col %d, row %d is outside of the %dx%d grid",
+				col, row, b.numCols, b.numRows)
+			continue
+		}
+		if !elem.Pos.EqualApprox(b.cellCenter(row, col)) {
+			b.errorf(elem, "element is not aligned to the grid (the nearest cell is col %d, row %d)", col, row)
+			continue
+		}
+		index := row*b.numCols + col
+		if other := b.elemByIndex[index]; other != nil {
+			b.errorf(elem, "cell at col %d, row %d is already occupied by %s", col, row, other.TileClass)
+			continue
 		}
 		elemList = append(elemList, elem)
-		b.elemByIndex[b.indexByPos(elem.Pos)] = elem
+		b.elemByIndex[index] = elem
 		if elem.TileClass == "elem_input" {
 			s.Entry = elem
 			numInputs++
@@ -222,10 +261,10 @@ func (b *SchemaBuilder) build() {
 	}
 
 	if !foundOutput {
-		panic(fmt.Errorf("expected at least 1 OUT (output) element, found 0"))
+		b.schemaErrorf("expected at least 1 OUT (output) element, found 0")
 	}
 	if numInputs != 1 {
-		panic(fmt.Errorf("expected exactly 1 IN (input) element, found %d", numInputs))
+		b.schemaErrorf("expected exactly 1 IN (input) element, found %d", numInputs)
 	}
 
 	id := 0
@@ -320,5 +359,14 @@ func (b *SchemaBuilder) build() {
 }
 
 func (b *SchemaBuilder) errorf(elem *SchemaElem, format string, args ...any) {
-	panic(fmt.Errorf("%v: %s: %s", elem.Pos, elem.TileClass, fmt.Sprintf(format, args...)))
+	b.diagnostics = append(b.diagnostics, NewElemDiagnostic(SeverityError, elem, format, args...))
+}
+
+func (b *SchemaBuilder) schemaErrorf(format string, args ...any) {
+	b.diagnostics = append(b.diagnostics, Diagnostic{
+		Severity: SeverityError,
+		Row:      -1,
+		Col:      -1,
+		Message:  fmt.Sprintf(format, args...),
+	})
 }
@@ -0,0 +1,184 @@
+package leveldata
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+func TestBuilderGridSize(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	// IN + 9 * (pipe + elem) + pipe + OUT = 21 columns.
+	classes := make([]string, 9)
+	for i := range classes {
+		classes[i] = "apply_add"
+	}
+
+	tmpl := newLinearTemplate(tileset, classes...)
+	if _, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build(); err == nil {
+		t.Fatal("expected the default grid to be too small")
+	} else if !strings.Contains(err.Error(), "outside of the 12x8 grid") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tmpl.NumCols = 21
+	tmpl.NumRows = 1
+	schema, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.NumCols != 21 || schema.NumRows != 1 {
+		t.Fatalf("unexpected grid size: %dx%d", schema.NumCols, schema.NumRows)
+	}
+	output, err := NewSchemaRunner().Exec(schema, "aaa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "jjj" {
+		t.Fatalf("unexpected output: %q", output)
+	}
+
+	tmpl.NumCols = MaxNumSchemaCols + 1
+	if _, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build(); err == nil {
+		t.Fatal("expected a grid size error")
+	}
+}
+
+func TestBuilderElemPlacement(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tests := []struct {
+		name   string
+		modify func(tmpl *SchemaTemplate)
+		err    string
+	}{
+		{
+			name: "overlap",
+			modify: func(tmpl *SchemaTemplate) {
+				// Put the second pipe on top of the transform.
+				tmpl.Elems[3].Pos = tmpl.Elems[2].Pos
+			},
+			err: "cell at col 2, row 0 is already occupied by apply_add",
+		},
+		{
+			name: "off-grid",
+			modify: func(tmpl *SchemaTemplate) {
+				tmpl.Elems[4].Pos.Y -= tileset.TileHeight
+			},
+			err: "element at col 4, row -1 is outside of the 12x8 grid",
+		},
+		{
+			name: "non-aligned",
+			modify: func(tmpl *SchemaTemplate) {
+				tmpl.Elems[2].Pos.X += 10
+			},
+			err: "element is not aligned to the grid (the nearest cell is col 2, row 0)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl := newLinearTemplate(tileset, "apply_add")
+			test.modify(tmpl)
+			_, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestBuilderDiagnostics(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tmpl := newLinearTemplate(tileset, "apply_add", "apply_sub")
+	tmpl.Elems[2].Pos.X += 10                  // Non-aligned apply_add
+	tmpl.Elems[len(tmpl.Elems)-1].Class = "??" // Unknown output class
+	_, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	list, ok := err.(DiagnosticList)
+	if !ok {
+		t.Fatalf("expected a DiagnosticList, got %T", err)
+	}
+
+	type diagKey struct {
+		class    string
+		row, col int
+	}
+	have := make(map[diagKey]bool)
+	for _, d := range list {
+		if d.Severity != SeverityError {
+			t.Fatalf("unexpected %s severity for %v", d.Severity, d)
+		}
+		have[diagKey{d.Class, d.Row, d.Col}] = true
+	}
+	want := []diagKey{
+		{"apply_add", 0, 2},
+		{"??", 0, 6},
+		{"", -1, -1}, // No output element
+	}
+	for _, k := range want {
+		if !have[k] {
+			t.Errorf("missing %v diagnostic in:\n%v", k, err)
+		}
+	}
+}
+
+func TestBuilderCondDiagnostics(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	const levelTemplate = `
+grid:
+.  .  A@270   P SUB/F P A
+IN P  IF      . .     . OUT
+.  .  SA@270~ P ADD/L P A@180~
+settings:
+num_keywords: 1
+keywords: abc ab
+props:
+2,1: %s
+`
+	tests := []struct {
+		props string
+		err   string
+	}{
+		{"cond_kind=has_prefx string_arg=ab", `unknown cond_kind "has_prefx"`},
+		{"cond_kind=len_eq int_arg=11", "len_eq: int_arg should be in [0, 10] range, found 11"},
+		{"cond_kind=len_gt int_arg=-1", "len_gt: int_arg should be in"},
+		{"cond_kind=has_prefix string_arg=a1", `has_prefix: string_arg can only contain a-z letters, found "a1"`},
+		{"cond_kind=has_suffix string_arg=AB", "has_suffix: string_arg can only contain a-z letters"},
+		{"cond_kind=has_prefix", "has_prefix: string_arg can't be empty"},
+		{"cond_kind=last_gt string_arg=ab", `last_gt: string_arg should be a single letter, found "ab"`},
+		{"cond_kind=last_gt", "last_gt: string_arg should be a single letter"},
+		{"cond_kind=contains_letter", "contains_letter: string_arg can't be empty"},
+	}
+	for _, test := range tests {
+		tmpl, err := LoadTextTemplate(tileset, []byte(fmt.Sprintf(levelTemplate, test.props)))
+		if err != nil {
+			t.Fatalf("%s: %v", test.props, err)
+		}
+		_, err = NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+		list, ok := err.(DiagnosticList)
+		if !ok {
+			t.Fatalf("%s: expected a DiagnosticList, got %T (%v)", test.props, err, err)
+		}
+		if len(list) != 1 {
+			t.Fatalf("%s: expected 1 diagnostic, got:\n%v", test.props, list)
+		}
+		d := list[0]
+		if d.Severity != SeverityError || d.Class != "elem_if" || d.Row != 1 || d.Col != 2 {
+			t.Errorf("%s: unexpected diagnostic position: %+v", test.props, d)
+		}
+		if !strings.Contains(d.Message, test.err) {
+			t.Errorf("%s: unexpected message: %s\nexpected: %s", test.props, d.Message, test.err)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package leveldata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// CampaignGridCols and CampaignGridRows describe the chapter select screen layout.
+const (
+	CampaignGridCols = 5
+	CampaignGridRows = 3
+)
+
+// CampaignManifestFilename is a campaign manifest file name.
+// The manifest is located in the campaign root folder.
+const CampaignManifestFilename = "campaign.json"
+
+// Campaign is a set of chapters that are unlocked one after another.
+//
+// Campaigns are described by a JSON manifest:
+//
+//	{
+//	  "name": "my_campaign",
+//	  "title": "My Campaign",
+//	  "chapters": [
+//	    {
+//	      "name": "intro",
+//	      "label": "1",
+//	      "keyword": "secret",
+//	      "levels": ["levels/first.json", "levels/second.json"],
+//	      "col": 0,
+//	      "row": 0
+//	    },
+//	    {
+//	      "name": "intro_bonus",
+//	      "label": "1+",
+//	      "requires": "intro",
+//	      "levels": ["levels/bonus.json"],
+//	      "col": 0,
+//	      "row": 1
+//	    }
+//	  ]
+//	}
+//
+// The level paths are relative to the manifest file.
+// A level name is its file name without an extension, it should be unique.
+type Campaign struct {
+	// Name is used as a campaign save data key.
+	Name  string `json:"name"`
+	Title string `json:"title"`
+
+	Chapters []CampaignChapter `json:"chapters"`
+}
+
+type CampaignChapter struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+
+	// Keyword is a secret keyword that is passed through all chapter levels.
+	// Chapters without a keyword are bonus chapters.
+	Keyword string `json:"keyword,omitempty"`
+
+	// Requires is a name of the chapter that unlocks this one.
+	// A story chapter is unlocked when all but one levels of the required chapter are completed.
+	// A bonus chapter is unlocked when the required chapter secret keyword is decoded.
+	// An empty value means that the chapter is available from the start.
+	Requires string `json:"requires,omitempty"`
+
+	Levels []string `json:"levels"`
+
+	// Col and Row is a chapter position on the chapter select screen.
+	Col int `json:"col"`
+	Row int `json:"row"`
+}
+
+func (c *CampaignChapter) IsBonus() bool { return c.Keyword == "" }
+
+// CampaignLevelName returns a level name for the campaign level path.
+func CampaignLevelName(levelPath string) string {
+	return strings.TrimSuffix(path.Base(levelPath), path.Ext(levelPath))
+}
+
+// DecodeCampaign parses the campaign manifest and checks its consistency.
+// It doesn't check the level files, see ValidateCampaign.
+func DecodeCampaign(data []byte) (*Campaign, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var c Campaign
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	if c.Title == "" {
+		c.Title = c.Name
+	}
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *Campaign) check() error {
+	if c.Name == "" {
+		return errors.New("campaign name can't be empty")
+	}
+	if len(c.Chapters) == 0 {
+		return errors.New("campaign has no chapters")
+	}
+
+	chapters := make(map[string]*CampaignChapter, len(c.Chapters))
+	levels := make(map[string]string)
+	cells := make(map[[2]int]string, len(c.Chapters))
+	for i := range c.Chapters {
+		chapter := &c.Chapters[i]
+		if chapter.Name == "" {
+			return fmt.Errorf("chapters[%d]: name can't be empty", i)
+		}
+		if chapters[chapter.Name] != nil {
+			return fmt.Errorf("chapter %s: duplicated name", chapter.Name)
+		}
+		chapters[chapter.Name] = chapter
+		if chapter.Label == "" {
+			return fmt.Errorf("chapter %s: label can't be empty", chapter.Name)
+		}
+		for _, ch := range chapter.Keyword {
+			if ch < 'a' || ch > 'z' {
+				return fmt.Errorf("chapter %s: keyword should consist of lowercase letters", chapter.Name)
+			}
+		}
+		if chapter.Col < 0 || chapter.Col >= CampaignGridCols || chapter.Row < 0 || chapter.Row >= CampaignGridRows {
+			return fmt.Errorf("chapter %s: %d,%d position is outside of the %dx%d grid",
+				chapter.Name, chapter.Col, chapter.Row, CampaignGridCols, CampaignGridRows)
+		}
+		cell := [2]int{chapter.Col, chapter.Row}
+		if other, ok := cells[cell]; ok {
+			return fmt.Errorf("chapter %s: %d,%d position is already occupied by %s",
+				chapter.Name, chapter.Col, chapter.Row, other)
+		}
+		cells[cell] = chapter.Name
+		if len(chapter.Levels) == 0 {
+			return fmt.Errorf("chapter %s: no levels", chapter.Name)
+		}
+		for _, levelPath := range chapter.Levels {
+			if !fs.ValidPath(levelPath) {
+				return fmt.Errorf("chapter %s: %q: invalid level path", chapter.Name, levelPath)
+			}
+			name := CampaignLevelName(levelPath)
+			if other, ok := levels[name]; ok {
+				return fmt.Errorf("chapter %s: %q: level name %q is already used by %q", chapter.Name, levelPath, name, other)
+			}
+			levels[name] = levelPath
+		}
+	}
+
+	for i := range c.Chapters {
+		chapter := &c.Chapters[i]
+		if chapter.Requires == "" {
+			continue
+		}
+		if chapters[chapter.Requires] == nil {
+			return fmt.Errorf("chapter %s: requires an unknown chapter %s", chapter.Name, chapter.Requires)
+		}
+		// Every chapter has at most one dependency, so a cycle
+		// can be detected by following the chain.
+		next := chapter.Requires
+		for steps := 0; next != ""; steps++ {
+			if next == chapter.Name || steps > len(c.Chapters) {
+				return fmt.Errorf("chapter %s: circular requires dependency", chapter.Name)
+			}
+			next = chapters[next].Requires
+		}
+	}
+
+	return nil
+}
+
+// ValidateCampaign checks all campaign levels.
+// The level paths are resolved using fsys.
+//
+// Every level should be valid (see ValidateLevelData) and every chapter
+// secret keyword chain should be well-formed (see TraceKeywordChains).
+// All problems are reported as a DiagnosticList error.
+// The keyword chains are only traced if all levels are valid,
+// they're returned even if some of them are not well-formed.
+func ValidateCampaign(tileset *Tileset, c *Campaign, fsys fs.FS) ([]KeywordChain, error) {
+	var diagnostics DiagnosticList
+	for i := range c.Chapters {
+		chapter := &c.Chapters[i]
+		for _, levelPath := range chapter.Levels {
+			levelData, err := fs.ReadFile(fsys, levelPath)
+			if err == nil {
+				err = ValidateLevelData(tileset, levelData)
+			}
+			if err == nil {
+				continue
+			}
+			for _, d := range ErrorDiagnostics(err) {
+				diagnostics = append(diagnostics, Diagnostic{
+					Severity: d.Severity,
+					Row:      -1,
+					Col:      -1,
+					Message:  fmt.Sprintf("chapter %s: %s: %v", chapter.Name, levelPath, d),
+				})
+			}
+		}
+	}
+	if len(diagnostics) != 0 {
+		return nil, diagnostics
+	}
+	return TraceKeywordChains(tileset, c, fsys)
+}
+
+// UnreferencedCampaignFiles returns the level files that are not used by the campaign.
+// All .json files inside fsys, except the manifest, are considered to be level files.
+func UnreferencedCampaignFiles(c *Campaign, fsys fs.FS) ([]string, error) {
+	referenced := make(map[string]bool)
+	for _, chapter := range c.Chapters {
+		for _, levelPath := range chapter.Levels {
+			referenced[levelPath] = true
+		}
+	}
+
+	var result []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".json" || p == CampaignManifestFilename {
+			return nil
+		}
+		if !referenced[p] {
+			result = append(result, p)
+		}
+		return nil
+	})
+	return result, err
+}
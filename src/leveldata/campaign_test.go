@@ -0,0 +1,209 @@
+package leveldata
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDecodeCampaignErrors(t *testing.T) {
+	tests := []struct {
+		manifest string
+		err      string
+	}{
+		{`{"chapters": []}`, "campaign name can't be empty"},
+		{`{"name": "c"}`, "campaign has no chapters"},
+		{`{"name": "c", "chapterz": []}`, `unknown field "chapterz"`},
+		{`{"name": "c", "chapters": [{"name": "a", "levels": ["a.json"]}]}`, "chapter a: label can't be empty"},
+		{`{"name": "c", "chapters": [{"name": "a", "label": "1"}]}`, "chapter a: no levels"},
+		{`{"name": "c", "chapters": [{"name": "a", "label": "1", "keyword": "Rain", "levels": ["a.json"]}]}`, "keyword should consist of lowercase letters"},
+		{`{"name": "c", "chapters": [{"name": "a", "label": "1", "levels": ["../a.json"]}]}`, `"../a.json": invalid level path`},
+		{`{"name": "c", "chapters": [{"name": "a", "label": "1", "levels": ["a.json"], "col": 5}]}`, "5,0 position is outside of the 5x3 grid"},
+		{
+			`{"name": "c", "chapters": [{"name": "a", "label": "1", "levels": ["a.json"]}, {"name": "a", "label": "2", "levels": ["b.json"], "col": 1}]}`,
+			"chapter a: duplicated name",
+		},
+		{
+			`{"name": "c", "chapters": [{"name": "a", "label": "1", "levels": ["a.json"]}, {"name": "b", "label": "2", "levels": ["b.json"]}]}`,
+			"chapter b: 0,0 position is already occupied by a",
+		},
+		{
+			`{"name": "c", "chapters": [{"name": "a", "label": "1", "levels": ["a.json"]}, {"name": "b", "label": "2", "levels": ["x/a.json"], "col": 1}]}`,
+			`chapter b: "x/a.json": level name "a" is already used by "a.json"`,
+		},
+		{
+			`{"name": "c", "chapters": [{"name": "a", "label": "1", "requires": "x", "levels": ["a.json"]}]}`,
+			"chapter a: requires an unknown chapter x",
+		},
+		{
+			`{"name": "c", "chapters": [{"name": "a", "label": "1", "requires": "b", "levels": ["a.json"]}, {"name": "b", "label": "2", "requires": "a", "levels": ["b.json"], "col": 1}]}`,
+			"chapter a: circular requires dependency",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := DecodeCampaign([]byte(test.manifest))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.manifest)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: unexpected error: %v", test.manifest, err)
+		}
+	}
+}
+
+func TestValidateCampaign(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	const manifest = `{
+		"name": "test",
+		"chapters": [
+			{
+				"name": "first",
+				"label": "1",
+				"keyword": "rain",
+				"levels": ["story/hello_world.json", "story/rinse_repeat.json", "story/add_or_sub.json"]
+			},
+			{
+				"name": "first_bonus",
+				"label": "1+",
+				"requires": "first",
+				"levels": ["bonus/spellbook.json"],
+				"row": 1
+			}
+		]
+	}`
+	c, err := DecodeCampaign([]byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Title != "test" {
+		t.Fatalf("expected the title to default to the name, found %q", c.Title)
+	}
+	if _, err := ValidateCampaign(tileset, c, os.DirFS("../_assets/levels")); err != nil {
+		t.Fatal(err)
+	}
+
+	helloWorld, err := os.ReadFile("../_assets/levels/story/hello_world.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"story/hello_world.json": {Data: helloWorld},
+	}
+	_, err = ValidateCampaign(tileset, c, fsys)
+	if err == nil || !strings.Contains(err.Error(), "chapter first: story/rinse_repeat.json: open story/rinse_repeat.json") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Every missing level is reported.
+	if diagnostics := ErrorDiagnostics(err); len(diagnostics) != 3 {
+		t.Fatalf("expected 3 diagnostics, found %d:\n%v", len(diagnostics), err)
+	}
+}
+
+func TestBuiltinCampaign(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	fsys := os.DirFS("../_assets/levels")
+	manifest, err := fs.ReadFile(fsys, CampaignManifestFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := DecodeCampaign(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateCampaign(tileset, c, fsys); err != nil {
+		t.Fatal(err)
+	}
+	unreferenced, err := UnreferencedCampaignFiles(c, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unreferenced) != 0 {
+		t.Fatalf("found unreferenced level files: %v", unreferenced)
+	}
+
+	numLevels := 0
+	for _, chapter := range c.Chapters {
+		numLevels += len(chapter.Levels)
+	}
+	unreferenced, err = UnreferencedCampaignFiles(&Campaign{}, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unreferenced) != numLevels {
+		t.Fatalf("expected %d unreferenced files, found %d", numLevels, len(unreferenced))
+	}
+}
+
+func TestTraceKeywordChains(t *testing.T) {
+	tileset := loadTestTileset(t)
+	fsys := os.DirFS("../_assets/levels")
+
+	tests := []struct {
+		keyword string
+		err     string
+	}{
+		{"gargoyle", `story/hello_world.json: secret keyword "gargoyle" is also a level keyword`},
+		{"abcdefghijklmn", `secret keyword "abcdefghijklmn" is not a valid input`},
+		{"rain", `story/missing.json: open story/missing.json`},
+	}
+	for _, test := range tests {
+		c := &Campaign{
+			Name: "test",
+			Chapters: []CampaignChapter{
+				{Name: "first", Label: "1", Keyword: test.keyword, Levels: []string{"story/hello_world.json", "story/missing.json"}},
+			},
+		}
+		chains, err := TraceKeywordChains(tileset, c, fsys)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("%s: unexpected error: %v\nexpected: %s", test.keyword, err, test.err)
+		}
+		// The chains are returned even if they're broken.
+		if len(chains) != 1 || chains[0].Keyword != test.keyword {
+			t.Fatalf("%s: unexpected chains: %+v", test.keyword, chains)
+		}
+	}
+
+	manifest, err := fs.ReadFile(fsys, CampaignManifestFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := DecodeCampaign(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chains, err := TraceKeywordChains(tileset, c, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chapters := make(map[string]CampaignChapter)
+	for _, chapter := range c.Chapters {
+		if !chapter.IsBonus() {
+			chapters[chapter.Name] = chapter
+		}
+	}
+	if len(chains) != len(chapters) {
+		t.Fatalf("expected %d chains, found %d", len(chapters), len(chains))
+	}
+	for _, chain := range chains {
+		chapter := chapters[chain.Chapter]
+		if len(chain.Links) != len(chapter.Levels) {
+			t.Fatalf("%s: expected %d links, found %d", chain.Chapter, len(chapter.Levels), len(chain.Links))
+		}
+		input := chain.Keyword
+		for _, link := range chain.Links {
+			if link.Input != input {
+				t.Fatalf("%s: %s: expected %q input, found %q", chain.Chapter, link.Level, input, link.Input)
+			}
+			input = link.Output
+		}
+		if chain.Encoded() != input {
+			t.Fatalf("%s: expected %q result, found %q", chain.Chapter, input, chain.Encoded())
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package leveldata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeywordCollision describes a keyword that has the same encoding as some other input.
+type KeywordCollision struct {
+	Keyword string
+	Other   string
+	Encoded string
+}
+
+// FindKeywordCollisions reports all pairs of keywords that produce the same output.
+//
+// A non-terminating keyword is reported as an error.
+func FindKeywordCollisions(schema *ComponentSchema, keywords []string) ([]KeywordCollision, error) {
+	encoded, err := encodeKeywords(schema, keywords)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []KeywordCollision
+	for i := range keywords {
+		for j := i + 1; j < len(keywords); j++ {
+			if encoded[i] == encoded[j] && keywords[i] != keywords[j] {
+				result = append(result, KeywordCollision{
+					Keyword: keywords[i],
+					Other:   keywords[j],
+					Encoded: encoded[i],
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// FindDictionaryCollisions reports dictionary words that are encoded
+// in the same way as one of the keywords.
+//
+// Words that can't be typed in by a player (too long, with non-letter chars)
+// are ignored, as well as the words that never reach the output.
+// Words are compared in the lower case.
+func FindDictionaryCollisions(schema *ComponentSchema, keywords, dictionary []string) ([]KeywordCollision, error) {
+	encoded, err := encodeKeywords(schema, keywords)
+	if err != nil {
+		return nil, err
+	}
+	keywordByEncoding := make(map[string][]string, len(keywords))
+	isKeyword := make(map[string]bool, len(keywords))
+	for i, k := range keywords {
+		keywordByEncoding[encoded[i]] = append(keywordByEncoding[encoded[i]], k)
+		isKeyword[k] = true
+	}
+
+	var result []KeywordCollision
+	runner := NewSchemaRunner()
+	for _, word := range dictionary {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if !isValidInput(word) || isKeyword[word] {
+			continue
+		}
+		output, err := runner.Exec(schema, word)
+		if err != nil {
+			continue
+		}
+		for _, k := range keywordByEncoding[output] {
+			result = append(result, KeywordCollision{
+				Keyword: k,
+				Other:   word,
+				Encoded: output,
+			})
+		}
+	}
+	return result, nil
+}
+
+func encodeKeywords(schema *ComponentSchema, keywords []string) ([]string, error) {
+	runner := NewSchemaRunner()
+	encoded := make([]string, len(keywords))
+	for i, k := range keywords {
+		output, err := runner.Exec(schema, k)
+		if err != nil {
+			return nil, fmt.Errorf("keyword %q: %w", k, err)
+		}
+		encoded[i] = output
+	}
+	return encoded, nil
+}
+
+func isValidInput(s string) bool {
+	if len(s) == 0 || len(s) > MaxInputLen {
+		return false
+	}
+	for _, ch := range []byte(s) {
+		if ch < 'a' || ch > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package leveldata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindCollisions(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	schema := newTextSchema(t, tileset, testLossyLevel)
+
+	collisions, err := FindKeywordCollisions(schema, []string{"yab", "cab", "zab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []KeywordCollision{
+		{Keyword: "yab", Other: "zab", Encoded: "zab"},
+	}
+	if !reflect.DeepEqual(collisions, want) {
+		t.Fatalf("unexpected keyword collisions:\nhave: %v\nwant: %v", collisions, want)
+	}
+
+	collisions, err = FindKeywordCollisions(schema, []string{"yab", "cab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collisions) != 0 {
+		t.Fatalf("unexpected keyword collisions: %v", collisions)
+	}
+
+	// The keywords and the invalid inputs are skipped.
+	dictionary := []string{" ZAB ", "dab", "yab", "toolongword", "za1"}
+	collisions, err = FindDictionaryCollisions(schema, []string{"yab", "cab"}, dictionary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(collisions, want) {
+		t.Fatalf("unexpected dictionary collisions:\nhave: %v\nwant: %v", collisions, want)
+	}
+}
@@ -0,0 +1,201 @@
+package leveldata
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type condArgKind int
+
+const (
+	condArgNone condArgKind = iota
+	condArgString
+	condArgLetter
+)
+
+// condKindInfo describes an elem_if cond_kind and its arguments.
+//
+// The builder uses this info to validate the elements,
+// the runner uses the eval func to compute the condition result.
+type condKindInfo struct {
+	name string
+
+	stringArg condArgKind
+
+	intArg    bool
+	minIntArg int
+	maxIntArg int
+
+	// lengthOnly is set for the conditions that only depend on the data length.
+	// Since transforms never change the length, the result of such
+	// condition is the same for all inputs of the same length.
+	lengthOnly bool
+
+	eval func(r *SchemaRunner, extra *IfElemExtra) bool
+}
+
+var condKindList = []*condKindInfo{
+	{
+		name:      "anagram",
+		stringArg: condArgString,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return checkAnagram(r.data, []byte(extra.StringArg))
+		},
+	},
+	{
+		name:      "eq",
+		stringArg: condArgString,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return bytes.Equal(r.data, []byte(extra.StringArg))
+		},
+	},
+	{
+		name:      "substr_count",
+		stringArg: condArgString,
+		intArg:    true,
+		minIntArg: 0,
+		maxIntArg: MaxInputLen,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return bytes.Count(r.data, []byte(extra.StringArg)) == extra.IntArg
+		},
+	},
+	{
+		name:      "contains_letter",
+		stringArg: condArgString,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return bytes.ContainsAny(r.data, extra.StringArg)
+		},
+	},
+	{
+		name:      "contains_substr",
+		stringArg: condArgString,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return bytes.Contains(r.data, []byte(extra.StringArg))
+		},
+	},
+	{
+		name:      "has_prefix",
+		stringArg: condArgString,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return bytes.HasPrefix(r.data, []byte(extra.StringArg))
+		},
+	},
+	{
+		name:      "has_suffix",
+		stringArg: condArgString,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return bytes.HasSuffix(r.data, []byte(extra.StringArg))
+		},
+	},
+	{
+		name:      "last_gt",
+		stringArg: condArgLetter,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			if len(r.data) == 0 {
+				return false
+			}
+			return r.data[len(r.data)-1] > extra.StringArg[0]
+		},
+	},
+	{
+		name:       "len_even",
+		lengthOnly: true,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return len(r.data)%2 == 0
+		},
+	},
+	{
+		name: "fnv_even",
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return FNVHash(r.data)%2 == 0
+		},
+	},
+	{
+		name:       "len_eq",
+		lengthOnly: true,
+		intArg:     true,
+		minIntArg:  0,
+		maxIntArg:  MaxInputLen,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return len(r.data) == extra.IntArg
+		},
+	},
+	{
+		name:       "len_lt",
+		lengthOnly: true,
+		intArg:     true,
+		minIntArg:  0,
+		maxIntArg:  MaxInputLen,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return len(r.data) < extra.IntArg
+		},
+	},
+	{
+		name:       "len_gt",
+		lengthOnly: true,
+		intArg:     true,
+		minIntArg:  0,
+		maxIntArg:  MaxInputLen,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return len(r.data) > extra.IntArg
+		},
+	},
+	{
+		name: "unchanged",
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return bytes.Equal(r.data, r.input)
+		},
+	},
+	{
+		name:       "fixed_cond",
+		lengthOnly: true,
+		intArg:     true,
+		minIntArg:  0,
+		maxIntArg:  1,
+		eval: func(r *SchemaRunner, extra *IfElemExtra) bool {
+			return extra.IntArg == 1
+		},
+	},
+}
+
+var condKindByName = func() map[string]*condKindInfo {
+	m := make(map[string]*condKindInfo, len(condKindList))
+	for _, info := range condKindList {
+		m[info.name] = info
+	}
+	return m
+}()
+
+func validateIfElemExtra(extra *IfElemExtra) error {
+	info := condKindByName[extra.CondKind]
+	if info == nil {
+		return fmt.Errorf("unknown cond_kind %q", extra.CondKind)
+	}
+
+	switch info.stringArg {
+	case condArgString:
+		if extra.StringArg == "" {
+			return fmt.Errorf("%s: string_arg can't be empty", info.name)
+		}
+	case condArgLetter:
+		if len(extra.StringArg) != 1 {
+			return fmt.Errorf("%s: string_arg should be a single letter, found %q", info.name, extra.StringArg)
+		}
+	}
+	if info.stringArg != condArgNone {
+		for _, ch := range []byte(extra.StringArg) {
+			if ch < 'a' || ch > 'z' {
+				return fmt.Errorf("%s: string_arg can only contain a-z letters, found %q", info.name, extra.StringArg)
+			}
+		}
+	}
+
+	if info.intArg {
+		if extra.IntArg < info.minIntArg || extra.IntArg > info.maxIntArg {
+			return fmt.Errorf("%s: int_arg should be in [%d, %d] range, found %d",
+				info.name, info.minIntArg, info.maxIntArg, extra.IntArg)
+		}
+	}
+
+	return nil
+}
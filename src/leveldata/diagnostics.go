@@ -0,0 +1,93 @@
+package leveldata
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/quasilyte/gmath"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Diagnostic is a level problem description.
+//
+// Element-related diagnostics have Class, Row and Col set.
+// For the schema-wide problems, Class is empty and Row/Col are -1.
+type Diagnostic struct {
+	Severity Severity  `json:"severity"`
+	Class    string    `json:"class,omitempty"`
+	Row      int       `json:"row"`
+	Col      int       `json:"col"`
+	Pos      gmath.Vec `json:"-"`
+	Message  string    `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	var parts []string
+	if d.Row != -1 && d.Col != -1 {
+		parts = append(parts, fmt.Sprintf("row %d, col %d", d.Row, d.Col))
+	}
+	if d.Class != "" {
+		parts = append(parts, d.Class)
+	}
+	parts = append(parts, d.Message)
+	return strings.Join(parts, ": ")
+}
+
+// NewElemDiagnostic creates a diagnostic for the given schema element.
+func NewElemDiagnostic(severity Severity, elem *SchemaElem, format string, args ...any) Diagnostic {
+	return Diagnostic{
+		Severity: severity,
+		Class:    elem.TileClass,
+		Row:      elem.Row,
+		Col:      elem.Col,
+		Pos:      elem.Pos,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// DiagnosticList is an error that holds several diagnostics.
+type DiagnosticList []Diagnostic
+
+func (l DiagnosticList) Error() string {
+	parts := make([]string, len(l))
+	for i, d := range l {
+		parts[i] = d.String()
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ErrorDiagnostics converts an error into a diagnostics list.
+//
+// If err is not a DiagnosticList, it's reported as a single schema-wide error.
+func ErrorDiagnostics(err error) DiagnosticList {
+	var list DiagnosticList
+	if errors.As(err, &list) {
+		return list
+	}
+	return DiagnosticList{{Severity: SeverityError, Row: -1, Col: -1, Message: err.Error()}}
+}
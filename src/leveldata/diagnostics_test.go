@@ -0,0 +1,35 @@
+package leveldata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiagnosticString(t *testing.T) {
+	tests := []struct {
+		d    Diagnostic
+		want string
+	}{
+		{
+			Diagnostic{Class: "elem_if", Row: 1, Col: 2, Message: "bad cond"},
+			"row 1, col 2: elem_if: bad cond",
+		},
+		{
+			Diagnostic{Class: "elem_if", Row: 0, Col: 0, Message: "bad cond"},
+			"row 0, col 0: elem_if: bad cond",
+		},
+		{
+			Diagnostic{Row: -1, Col: -1, Message: "no output"},
+			"no output",
+		},
+		{
+			ErrorDiagnostics(errors.New("bad json"))[0],
+			"bad json",
+		},
+	}
+	for _, test := range tests {
+		if have := test.d.String(); have != test.want {
+			t.Errorf("unexpected result:\nhave: %q\nwant: %q", have, test.want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package leveldata
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/quasilyte/gmath"
+)
+
+// SchemaStats describes how hard it is to decipher a component schema.
+// See EstimateDifficulty.
+type SchemaStats struct {
+	// NumBranches is the number of reachable elements that
+	// can send the signal in two directions.
+	// The conditions that are always true (or false) are not counted.
+	NumBranches int
+
+	// LoopDepth is the max number of nested loops.
+	// The loops are usually formed by the countdowns and repeaters.
+	LoopDepth int
+
+	// NumLossy is the number of reachable transforms that can map
+	// different inputs to the same output.
+	NumLossy int
+
+	// CollisionRate is a share of the checked words that
+	// are encoded in the same way as one of the keywords.
+	CollisionRate float64
+
+	// AvgPathLen is the average number of elements a keyword
+	// goes through, pipes are not counted.
+	AvgPathLen float64
+
+	// NumElemKinds is the number of distinct reachable operations a player needs to learn.
+	// Transforms are distinguished by their op and scope, conditions by their kind.
+	// Pipes, inputs and outputs are not counted.
+	NumElemKinds int
+
+	// Score is a weighted sum of the other stats.
+	// The levels with a higher score are harder.
+	Score float64
+}
+
+// EstimateDifficulty computes the schema stats.
+//
+// The CollisionRate is measured on the dictionary words.
+// If the dictionary is empty, the keywords with one letter
+// replaced are used instead: they're similar to what a player
+// can type in while trying to decode a keyword.
+//
+// A non-terminating keyword is reported as an error.
+func EstimateDifficulty(schema *ComponentSchema, dictionary []string) (SchemaStats, error) {
+	var stats SchemaStats
+
+	reachable := make([]bool, len(schema.Elems))
+	walkReachable(schema.Entry, reachable)
+
+	elemKinds := make(map[string]struct{})
+	for _, e := range schema.Elems {
+		if !reachable[e.ElemID] {
+			// The elements behind an always true (or false) condition
+			// are never executed, a player doesn't need to decipher them.
+			continue
+		}
+		switch extra := e.ExtraData.(type) {
+		case *TransformElemExtra:
+			if !extra.IsInvertible() {
+				stats.NumLossy++
+			}
+			elemKinds["transform:"+extra.Op+":"+extra.Scope] = struct{}{}
+		case *IfElemExtra:
+			elemKinds[e.TileClass+":"+extra.CondKind] = struct{}{}
+		default:
+			switch e.Kind {
+			case InputElem, OutputElem, SimplePipeElem, PipeConnect2Elem:
+				// Not counted.
+			default:
+				elemKinds[e.TileClass] = struct{}{}
+			}
+		}
+		if e.Kind == IfElem && len(staticSuccessors(e)) == 2 {
+			stats.NumBranches++
+		}
+	}
+	stats.NumElemKinds = len(elemKinds)
+	stats.LoopDepth = loopDepth(schema, reachable)
+
+	pathLen := 0
+	runner := NewSchemaRunner()
+	runner.Trace = func(e *SchemaElem, branch int) {
+		switch e.Kind {
+		case SimplePipeElem, PipeConnect2Elem:
+			return
+		}
+		pathLen++
+	}
+	for _, k := range schema.Keywords {
+		if _, err := runner.Exec(schema, k); err != nil {
+			return stats, err
+		}
+	}
+	runner.Trace = nil
+	if len(schema.Keywords) != 0 {
+		stats.AvgPathLen = float64(pathLen) / float64(len(schema.Keywords))
+	}
+
+	collisionRate, err := keywordCollisionRate(runner, schema, dictionary)
+	if err != nil {
+		return stats, err
+	}
+	stats.CollisionRate = collisionRate
+
+	stats.Score = float64(stats.NumBranches) +
+		2*float64(stats.LoopDepth) +
+		1.5*float64(stats.NumLossy) +
+		10*stats.CollisionRate +
+		0.25*stats.AvgPathLen +
+		0.5*float64(stats.NumElemKinds)
+
+	return stats, nil
+}
+
+// ChapterDifficulty is an average difficulty score of the chapter levels.
+type ChapterDifficulty struct {
+	Chapter string
+	Score   float64
+}
+
+// EstimateCampaignDifficulty computes the chapter difficulty scores,
+// in the campaign manifest order. See EstimateDifficulty.
+func EstimateCampaignDifficulty(tileset *Tileset, c *Campaign, fsys fs.FS, dictionary []string) ([]ChapterDifficulty, error) {
+	result := make([]ChapterDifficulty, 0, len(c.Chapters))
+	for _, chapter := range c.Chapters {
+		score := 0.0
+		for _, levelPath := range chapter.Levels {
+			schema, err := loadCampaignLevel(tileset, fsys, levelPath)
+			if err != nil {
+				return nil, fmt.Errorf("chapter %s: %s: %w", chapter.Name, levelPath, err)
+			}
+			stats, err := EstimateDifficulty(schema, dictionary)
+			if err != nil {
+				return nil, fmt.Errorf("chapter %s: %s: %w", chapter.Name, levelPath, err)
+			}
+			score += stats.Score
+		}
+		result = append(result, ChapterDifficulty{
+			Chapter: chapter.Name,
+			Score:   score / float64(len(chapter.Levels)),
+		})
+	}
+	return result, nil
+}
+
+// CheckChapterProgression reports the chapters that are easier than
+// the chapter that unlocks them as warnings.
+// The difficulty slice is expected to be an EstimateCampaignDifficulty result.
+func CheckChapterProgression(c *Campaign, difficulty []ChapterDifficulty) DiagnosticList {
+	scores := make(map[string]float64, len(difficulty))
+	for _, d := range difficulty {
+		scores[d.Chapter] = d.Score
+	}
+	var result DiagnosticList
+	for _, chapter := range c.Chapters {
+		if chapter.Requires == "" {
+			continue
+		}
+		score := scores[chapter.Name]
+		requiredScore := scores[chapter.Requires]
+		if score < requiredScore {
+			result = append(result, Diagnostic{
+				Severity: SeverityWarning,
+				Row:      -1,
+				Col:      -1,
+				Message: fmt.Sprintf("chapter %s: difficulty score %.1f is lower than the required chapter %s score %.1f",
+					chapter.Name, score, chapter.Requires, requiredScore),
+			})
+		}
+	}
+	return result
+}
+
+func keywordCollisionRate(runner *SchemaRunner, schema *ComponentSchema, dictionary []string) (float64, error) {
+	encoded, err := encodeKeywords(schema, schema.Keywords)
+	if err != nil {
+		return 0, err
+	}
+	isEncodedKeyword := make(map[string]bool, len(encoded))
+	for _, s := range encoded {
+		isEncodedKeyword[s] = true
+	}
+
+	words := make(map[string]bool)
+	if len(dictionary) != 0 {
+		for _, w := range dictionary {
+			words[strings.ToLower(strings.TrimSpace(w))] = true
+		}
+	} else {
+		for _, k := range schema.Keywords {
+			variant := []byte(k)
+			for i := range variant {
+				for ch := byte('a'); ch <= 'z'; ch++ {
+					if ch == k[i] {
+						continue
+					}
+					variant[i] = ch
+					words[string(variant)] = true
+				}
+				variant[i] = k[i]
+			}
+		}
+	}
+	for _, k := range schema.Keywords {
+		delete(words, k)
+	}
+
+	numChecked := 0
+	numCollisions := 0
+	for w := range words {
+		if !isValidInput(w) {
+			continue
+		}
+		output, err := runner.Exec(schema, w)
+		if err != nil {
+			continue
+		}
+		numChecked++
+		if isEncodedKeyword[output] {
+			numCollisions++
+		}
+	}
+	if numChecked == 0 {
+		return 0, nil
+	}
+	return float64(numCollisions) / float64(numChecked), nil
+}
+
+// loopDepth returns the max loop nesting level of the reachable schema elements.
+//
+// Every strongly connected component of the schema graph is a loop.
+// The nested loops are found by removing the loop entries
+// (the elements the signal enters the loop through) and repeating the search.
+func loopDepth(schema *ComponentSchema, enabled []bool) int {
+	depth := 0
+	for _, loop := range findLoops(schema, enabled) {
+		inLoop := make([]bool, len(schema.Elems))
+		for _, e := range loop {
+			inLoop[e.ElemID] = true
+		}
+		inner := make([]bool, len(schema.Elems))
+		copy(inner, inLoop)
+		numEntries := 0
+		for _, e := range schema.Elems {
+			if inLoop[e.ElemID] {
+				continue
+			}
+			for _, next := range staticSuccessors(e) {
+				if inLoop[next.ElemID] && inner[next.ElemID] {
+					inner[next.ElemID] = false
+					numEntries++
+				}
+			}
+		}
+		if numEntries == 0 {
+			// The loop contains the schema entry.
+			inner[loop[0].ElemID] = false
+		}
+		depth = gmath.ClampMin(depth, 1+loopDepth(schema, inner))
+	}
+	return depth
+}
+
+// findLoops returns the strongly connected components of the enabled elements
+// that have a cycle inside them. It uses the Tarjan's algorithm.
+func findLoops(schema *ComponentSchema, enabled []bool) [][]*SchemaElem {
+	type elemInfo struct {
+		index   int
+		lowlink int
+		onStack bool
+	}
+	info := make([]elemInfo, len(schema.Elems))
+	nextIndex := 1
+	var stack []*SchemaElem
+	var result [][]*SchemaElem
+
+	var visit func(e *SchemaElem)
+	visit = func(e *SchemaElem) {
+		ei := &info[e.ElemID]
+		ei.index = nextIndex
+		ei.lowlink = nextIndex
+		nextIndex++
+		stack = append(stack, e)
+		ei.onStack = true
+
+		selfLoop := false
+		for _, next := range staticSuccessors(e) {
+			if !enabled[next.ElemID] {
+				continue
+			}
+			if next == e {
+				selfLoop = true
+			}
+			ni := &info[next.ElemID]
+			if ni.index == 0 {
+				visit(next)
+				ei.lowlink = gmath.ClampMax(ei.lowlink, ni.lowlink)
+			} else if ni.onStack {
+				ei.lowlink = gmath.ClampMax(ei.lowlink, ni.index)
+			}
+		}
+
+		if ei.lowlink != ei.index {
+			return
+		}
+		var component []*SchemaElem
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			info[top.ElemID].onStack = false
+			component = append(component, top)
+			if top == e {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			result = append(result, component)
+		}
+	}
+
+	for _, e := range schema.Elems {
+		if enabled[e.ElemID] && info[e.ElemID].index == 0 {
+			visit(e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package leveldata
+
+import (
+	"os"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+func TestEstimateDifficulty(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tests := []struct {
+		level        string
+		branches     int
+		loopDepth    int
+		lossy        int
+		elemKinds    int
+		hasCollision bool
+	}{
+		{"story/hello_world", 0, 0, 0, 1, false},
+		{"story/binary_tree", 7, 0, 2, 8, true},
+		// The elements behind the fixed condition are not counted.
+		{"story/fixed_cond", 0, 0, 0, 4, false},
+		{"bonus/sub_loop", 3, 1, 0, 5, false},
+		{"bonus/lossy_conversion", 0, 0, 2, 2, true},
+	}
+	for _, test := range tests {
+		levelData, err := os.ReadFile("../_assets/levels/" + test.level + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := LoadLevelTemplate(tileset, levelData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := EstimateDifficulty(schema, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.level, err)
+		}
+		if stats.NumBranches != test.branches {
+			t.Errorf("%s: branches: have %d, want %d", test.level, stats.NumBranches, test.branches)
+		}
+		if stats.LoopDepth != test.loopDepth {
+			t.Errorf("%s: loop depth: have %d, want %d", test.level, stats.LoopDepth, test.loopDepth)
+		}
+		if stats.NumLossy != test.lossy {
+			t.Errorf("%s: lossy: have %d, want %d", test.level, stats.NumLossy, test.lossy)
+		}
+		if stats.NumElemKinds != test.elemKinds {
+			t.Errorf("%s: elem kinds: have %d, want %d", test.level, stats.NumElemKinds, test.elemKinds)
+		}
+		if (stats.CollisionRate != 0) != test.hasCollision {
+			t.Errorf("%s: unexpected collision rate %f", test.level, stats.CollisionRate)
+		}
+		if stats.AvgPathLen < 3 {
+			t.Errorf("%s: avg path len is too small: %f", test.level, stats.AvgPathLen)
+		}
+	}
+}
+
+func TestEstimateDifficultyLinear(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	schema := newLinearSchema(t, tileset, "apply_add", "apply_reverse", "apply_add")
+	schema.Keywords = []string{"abc", "hello"}
+
+	stats, err := EstimateDifficulty(schema, []string{"abd", "xyz", "Hello", "not a word"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SchemaStats{
+		AvgPathLen:   5, // input, 3 transforms and output
+		NumElemKinds: 2,
+		Score:        5*0.25 + 2*0.5,
+	}
+	if stats != want {
+		t.Fatalf("unexpected stats:\nhave: %+v\nwant: %+v", stats, want)
+	}
+
+	// Without keywords, only the static analysis is performed.
+	schema.Keywords = nil
+	stats, err = EstimateDifficulty(schema, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.AvgPathLen != 0 || stats.NumElemKinds != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestLoopDepth(t *testing.T) {
+	newSchema := func(edges [][]int) *ComponentSchema {
+		schema := &ComponentSchema{}
+		for i := range edges {
+			schema.Elems = append(schema.Elems, &SchemaElem{ElemID: i, Kind: SimplePipeElem})
+		}
+		for i, next := range edges {
+			for _, j := range next {
+				schema.Elems[i].Next = append(schema.Elems[i].Next, schema.Elems[j])
+			}
+		}
+		schema.Entry = schema.Elems[0]
+		return schema
+	}
+
+	tests := []struct {
+		edges [][]int
+		depth int
+	}{
+		// 0 -> 1 -> 2
+		{[][]int{{1}, {2}, {}}, 0},
+		// 0 -> 1 -> 1 -> 2
+		{[][]int{{1}, {1, 2}, {}}, 1},
+		// 0 -> 1 -> 2 -> 1 -> 3 -> 4 -> 3 -> 5
+		{[][]int{{1}, {2}, {1, 3}, {4}, {3, 5}, {}}, 1},
+		// 0 -> 1 -> 2 -> 3 -> 2 -> 4 -> 1 -> 5
+		{[][]int{{1}, {2, 5}, {3, 4}, {2}, {1}, {}}, 2},
+		// 0 -> 1 -> 2 -> 3 -> 3 -> 2 -> 1 -> 4
+		{[][]int{{1}, {2, 4}, {3, 1}, {3, 2}, {}}, 3},
+	}
+	for i, test := range tests {
+		schema := newSchema(test.edges)
+		reachable := make([]bool, len(schema.Elems))
+		walkReachable(schema.Entry, reachable)
+		if depth := loopDepth(schema, reachable); depth != test.depth {
+			t.Errorf("tests[%d]: loop depth is %d, expected %d", i, depth, test.depth)
+		}
+	}
+}
+
+func TestCheckChapterProgression(t *testing.T) {
+	c := &Campaign{
+		Name: "test",
+		Chapters: []CampaignChapter{
+			{Name: "first", Keyword: "a"},
+			{Name: "second", Keyword: "b", Requires: "first"},
+			{Name: "second_bonus", Requires: "second"},
+		},
+	}
+
+	tests := []struct {
+		scores []float64
+		want   []string
+	}{
+		{[]float64{1, 2, 3}, nil},
+		{[]float64{1, 1, 1}, nil},
+		{[]float64{2, 1, 3}, []string{"chapter second: difficulty score 1.0 is lower than the required chapter first score 2.0"}},
+		{[]float64{1, 3, 2.5}, []string{"chapter second_bonus: difficulty score 2.5 is lower than the required chapter second score 3.0"}},
+	}
+	for _, test := range tests {
+		difficulty := make([]ChapterDifficulty, len(c.Chapters))
+		for i, chapter := range c.Chapters {
+			difficulty[i] = ChapterDifficulty{Chapter: chapter.Name, Score: test.scores[i]}
+		}
+		diagnostics := CheckChapterProgression(c, difficulty)
+		if len(diagnostics) != len(test.want) {
+			t.Errorf("%v: unexpected diagnostics:\n%v", test.scores, diagnostics)
+			continue
+		}
+		for i, d := range diagnostics {
+			if d.Severity != SeverityWarning || d.Message != test.want[i] {
+				t.Errorf("%v: unexpected diagnostic: %v", test.scores, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,537 @@
+package leveldata
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quasilyte/gmath"
+)
+
+// MaxGeneratorDifficulty is the highest GeneratorConfig.Difficulty value.
+const MaxGeneratorDifficulty = 5
+
+// DefaultGeneratorMaxAttempts is used when GeneratorConfig.MaxAttempts is 0.
+const DefaultGeneratorMaxAttempts = 100
+
+// GeneratorNumKeywords is the number of keywords a generated level has.
+const GeneratorNumKeywords = 6
+
+type GeneratorConfig struct {
+	// Seed makes the generation reproducible:
+	// the same config always produces the same level.
+	Seed int64
+
+	// Difficulty is a value from 1 to MaxGeneratorDifficulty.
+	// It defines the module budget along with the modules, conditions
+	// and transforms the generator can use.
+	Difficulty int
+
+	// Words is a list of keyword candidates, at least GeneratorNumKeywords words.
+	// The words that are not valid inputs are ignored.
+	Words []string
+
+	// NumKeywords is the number of keywords a player needs to decode.
+	// Zero value means 2.
+	NumKeywords int
+
+	// NumCols and NumRows describe the schema grid size.
+	// Zero values mean DefaultNumSchemaCols and DefaultNumSchemaRows.
+	NumCols int
+	NumRows int
+
+	// MaxAttempts limits the number of levels the generator can try.
+	// Zero value means DefaultGeneratorMaxAttempts.
+	MaxAttempts int
+}
+
+// GenerateLevel creates a random level out of the schema modules:
+// linear transforms, if/else diamonds, countdown loops and mux joins.
+//
+// Every module has a cost, the difficulty defines the total budget.
+// The modules are chained in lanes that go left to right and then back,
+// so the level stays inside the grid.
+//
+// Every generated level is checked by the SchemaBuilder and the SchemaRunner:
+// all its keywords terminate, they have distinct encodings and,
+// together, they execute every schema element (see Lint).
+// If a generated level fails these checks, the generator tries again.
+func GenerateLevel(tileset *Tileset, config GeneratorConfig) (*SchemaTemplate, error) {
+	if config.Difficulty < 1 || config.Difficulty > MaxGeneratorDifficulty {
+		return nil, fmt.Errorf("invalid difficulty %d, expected a value from 1 to %d",
+			config.Difficulty, MaxGeneratorDifficulty)
+	}
+	if config.NumKeywords == 0 {
+		config.NumKeywords = 2
+	}
+	if config.NumKeywords < 1 || config.NumKeywords > GeneratorNumKeywords {
+		return nil, fmt.Errorf("invalid number of keywords %d, expected a value from 1 to %d",
+			config.NumKeywords, GeneratorNumKeywords)
+	}
+	if config.NumCols == 0 && config.NumRows == 0 {
+		config.NumCols = DefaultNumSchemaCols
+		config.NumRows = DefaultNumSchemaRows
+	}
+	if config.NumCols < minGeneratorCols || config.NumCols > MaxNumSchemaCols ||
+		config.NumRows < minGeneratorRows || config.NumRows > MaxNumSchemaRows {
+		return nil, fmt.Errorf("invalid grid size %dx%d: expected a size from %dx%d to %dx%d",
+			config.NumCols, config.NumRows, minGeneratorCols, minGeneratorRows, MaxNumSchemaCols, MaxNumSchemaRows)
+	}
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = DefaultGeneratorMaxAttempts
+	}
+
+	g := newLevelGenerator(tileset, config)
+	if len(g.words) < GeneratorNumKeywords {
+		return nil, fmt.Errorf("expected at least %d valid words, found %d", GeneratorNumKeywords, len(g.words))
+	}
+	if len(g.transforms) < minGeneratorTransforms {
+		return nil, fmt.Errorf("expected at least %d transforms for difficulty %d, found %d",
+			minGeneratorTransforms, config.Difficulty, len(g.transforms))
+	}
+
+	var err error
+	for i := 0; i < config.MaxAttempts; i++ {
+		var result *SchemaTemplate
+		result, err = g.tryGenerate()
+		if err == nil {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("no valid level after %d attempts, the last problem: %w", config.MaxAttempts, err)
+}
+
+// DailyGeneratorSeed returns a GeneratorConfig.Seed for the given date.
+// The date is taken in UTC, so everyone gets the same seed during the day.
+func DailyGeneratorSeed(t time.Time) int64 {
+	year, month, day := t.UTC().Date()
+	return int64(year*10000 + int(month)*100 + day)
+}
+
+const (
+	// The generated levels need at least one module between the input and the output.
+	minGeneratorCols = 5
+	minGeneratorRows = 3
+
+	// There should be a transform that neither repeats nor reverts the previous one.
+	minGeneratorTransforms = 3
+
+	// newTransform gives up after this many rejected transforms,
+	// so an unlucky random sequence can't make it loop for too long.
+	maxGeneratorTransformRetries = 20
+
+	// Every lane occupies 3 rows: the main row along with the rows above and below it.
+	// The lanes are separated by an empty row, so their elements are never connected.
+	generatorLaneStep = 4
+)
+
+type generatorModuleKind int
+
+const (
+	generatorTransformModule generatorModuleKind = iota
+	generatorMuxModule
+	generatorDiamondModule
+	generatorLoopModule
+)
+
+type generatorModuleInfo struct {
+	kind          generatorModuleKind
+	cost          int
+	width         int
+	minDifficulty int
+}
+
+var generatorModules = []generatorModuleInfo{
+	{kind: generatorTransformModule, cost: 1, width: 2, minDifficulty: 1},
+	{kind: generatorMuxModule, cost: 2, width: 4, minDifficulty: 2},
+	{kind: generatorDiamondModule, cost: 3, width: 2, minDifficulty: 2},
+	{kind: generatorLoopModule, cost: 3, width: 3, minDifficulty: 3},
+}
+
+type generatorCond struct {
+	minDifficulty int
+	gen           func(r *gmath.Rand) nativeIfExtra
+}
+
+// The easiest conditions only depend on the data length,
+// so the level can still be decoded analytically (see Decode).
+var generatorConds = []generatorCond{
+	{minDifficulty: 2, gen: func(r *gmath.Rand) nativeIfExtra {
+		return nativeIfExtra{CondKind: "len_even"}
+	}},
+	{minDifficulty: 2, gen: func(r *gmath.Rand) nativeIfExtra {
+		return nativeIfExtra{CondKind: "len_gt", IntArg: r.IntRange(3, 7)}
+	}},
+	{minDifficulty: 2, gen: func(r *gmath.Rand) nativeIfExtra {
+		return nativeIfExtra{CondKind: "len_lt", IntArg: r.IntRange(4, 8)}
+	}},
+	{minDifficulty: 3, gen: func(r *gmath.Rand) nativeIfExtra {
+		return nativeIfExtra{CondKind: "contains_letter", StringArg: gmath.RandElem(r, []string{"a", "e", "i", "o", "u"})}
+	}},
+	{minDifficulty: 3, gen: func(r *gmath.Rand) nativeIfExtra {
+		return nativeIfExtra{CondKind: "last_gt", StringArg: string(rune(r.IntRange('f', 's')))}
+	}},
+	{minDifficulty: 4, gen: func(r *gmath.Rand) nativeIfExtra {
+		return nativeIfExtra{CondKind: "fnv_even"}
+	}},
+}
+
+type levelGenerator struct {
+	tileset *Tileset
+	config  GeneratorConfig
+	rand    gmath.Rand
+	runner  *SchemaRunner
+
+	words      []string
+	transforms []*ElemInfo
+	conds      []generatorCond
+
+	elems []nativeElem
+
+	// The current lane state.
+	// The main row elements are placed at the cursor column, one after another.
+	// dir is 1 for the lanes that go to the right and -1 for the other ones.
+	lane   int
+	cursor int
+	dir    int
+
+	// prevTransform is used to avoid the transforms that undo each other.
+	prevTransform *ElemInfo
+}
+
+func newLevelGenerator(tileset *Tileset, config GeneratorConfig) *levelGenerator {
+	g := &levelGenerator{
+		tileset: tileset,
+		config:  config,
+		runner:  NewSchemaRunner(),
+	}
+	g.rand.SetSeed(config.Seed)
+
+	seen := make(map[string]bool, len(config.Words))
+	for _, w := range config.Words {
+		if !isValidInput(w) || seen[w] {
+			continue
+		}
+		seen[w] = true
+		g.words = append(g.words, w)
+	}
+
+	for _, info := range tileset.Elems() {
+		if info.Kind != TransformElem || info.IsConfigurable() {
+			continue
+		}
+		switch {
+		case config.Difficulty <= 2 && (info.Transform.Scope != "" || !info.Transform.IsInvertible()):
+			continue
+		case config.Difficulty == 3 && !info.Transform.IsInvertible():
+			continue
+		}
+		g.transforms = append(g.transforms, info)
+	}
+
+	for _, cond := range generatorConds {
+		if cond.minDifficulty <= config.Difficulty {
+			g.conds = append(g.conds, cond)
+		}
+	}
+
+	return g
+}
+
+func (g *levelGenerator) tryGenerate() (*SchemaTemplate, error) {
+	g.layout()
+
+	result := &SchemaTemplate{
+		Tileset:     g.tileset,
+		NumCols:     g.config.NumCols,
+		NumRows:     g.config.NumRows,
+		NumKeywords: g.config.NumKeywords,
+	}
+	for _, e := range g.elems {
+		elem, err := newNativeTemplateElem(g.tileset, e)
+		if err != nil {
+			return nil, err
+		}
+		result.Elems = append(result.Elems, elem)
+	}
+
+	schema, err := NewSchemaBuilder(gmath.Vec{}, result).Build()
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := g.pickKeywords(schema)
+	if err != nil {
+		return nil, err
+	}
+	schema.Keywords = keywords
+	if diagnostics := Lint(schema); len(diagnostics) != 0 {
+		return nil, diagnostics
+	}
+
+	result.Keywords = keywords
+	return result, nil
+}
+
+// pickKeywords selects the words that have distinct encodings.
+// An encoding can't match any of the selected words either.
+func (g *levelGenerator) pickKeywords(schema *ComponentSchema) ([]string, error) {
+	words := append([]string{}, g.words...)
+	gmath.Shuffle(&g.rand, words)
+
+	keywords := make([]string, 0, GeneratorNumKeywords)
+	used := make(map[string]bool, 2*GeneratorNumKeywords)
+	for _, w := range words {
+		encoded, err := g.runner.Exec(schema, w)
+		if err != nil {
+			return nil, fmt.Errorf("keyword %q: %w", w, err)
+		}
+		if encoded == w || used[w] || used[encoded] {
+			continue
+		}
+		used[w] = true
+		used[encoded] = true
+		keywords = append(keywords, w)
+		if len(keywords) == GeneratorNumKeywords {
+			return keywords, nil
+		}
+	}
+	return nil, fmt.Errorf("found only %d words with distinct encodings", len(keywords))
+}
+
+func (g *levelGenerator) layout() {
+	g.elems = g.elems[:0]
+	g.lane = 0
+	g.cursor = 0
+	g.dir = 1
+	g.prevTransform = nil
+
+	g.place(2, []nativeElem{
+		{Class: "elem_input"},
+		{Class: "pipe", Col: 1},
+	})
+
+	budget := 1 + 2*g.config.Difficulty
+	candidates := make([]generatorModuleInfo, 0, len(generatorModules))
+	for budget > 0 {
+		candidates = candidates[:0]
+		for _, m := range generatorModules {
+			if m.cost > budget || m.minDifficulty > g.config.Difficulty {
+				continue
+			}
+			if g.fits(m.width) || g.fitsAfterTurn(m.width) {
+				candidates = append(candidates, m)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		m := gmath.RandElem(&g.rand, candidates)
+		if !g.fits(m.width) {
+			g.turn()
+		}
+		g.place(m.width, g.newModule(m.kind))
+		budget -= m.cost
+	}
+
+	g.place(1, []nativeElem{{Class: "elem_output"}})
+	g.center()
+}
+
+func (g *levelGenerator) laneRow(lane int) int {
+	return 1 + lane*generatorLaneStep
+}
+
+// fits reports whether a module of the given width can be placed in the current lane.
+// The column after the module is reserved for the output or the lane turn.
+func (g *levelGenerator) fits(width int) bool {
+	end := g.cursor + g.dir*width
+	return end >= 0 && end < g.config.NumCols
+}
+
+func (g *levelGenerator) fitsAfterTurn(width int) bool {
+	if g.laneRow(g.lane+1)+1 >= g.config.NumRows {
+		return false
+	}
+	end := g.cursor - g.dir - g.dir*width
+	return end >= 0 && end < g.config.NumCols
+}
+
+// turn connects the current lane with the next one using the cursor column.
+func (g *levelGenerator) turn() {
+	col := g.cursor
+	row := g.laneRow(g.lane)
+	nextRow := g.laneRow(g.lane + 1)
+	if g.dir > 0 {
+		g.elems = append(g.elems, nativeElem{Class: "angle_pipe", Col: col, Row: row})
+	} else {
+		g.elems = append(g.elems, nativeElem{Class: "angle_pipe", Col: col, Row: row, FlipHorizontally: true})
+	}
+	for r := row + 1; r < nextRow; r++ {
+		g.elems = append(g.elems, nativeElem{Class: "pipe", Col: col, Row: r, Rotation: 90})
+	}
+	if g.dir > 0 {
+		g.elems = append(g.elems, nativeElem{Class: "angle_pipe", Col: col, Row: nextRow, Rotation: 90})
+	} else {
+		g.elems = append(g.elems, nativeElem{Class: "angle_pipe", Col: col, Row: nextRow, Rotation: 270, FlipHorizontally: true})
+	}
+	g.lane++
+	g.dir = -g.dir
+	g.cursor = col + g.dir
+}
+
+// place adds the module elements to the current lane and moves the cursor.
+//
+// The module elements use the local coordinates: the module starts at 0,0
+// and goes to the right. The lanes that go to the left have their modules
+// rotated by 180 degrees.
+func (g *levelGenerator) place(width int, module []nativeElem) {
+	row := g.laneRow(g.lane)
+	for _, e := range module {
+		e.Col = g.cursor + g.dir*e.Col
+		e.Row = row + g.dir*e.Row
+		if g.dir < 0 {
+			e.Rotation = (e.Rotation + 180) % 360
+		}
+		g.elems = append(g.elems, e)
+	}
+	g.cursor += g.dir * width
+}
+
+// center moves the elements to the middle of the grid.
+func (g *levelGenerator) center() {
+	minCol, minRow := g.config.NumCols, g.config.NumRows
+	maxCol, maxRow := 0, 0
+	for _, e := range g.elems {
+		minCol = gmath.ClampMax(minCol, e.Col)
+		minRow = gmath.ClampMax(minRow, e.Row)
+		maxCol = gmath.ClampMin(maxCol, e.Col)
+		maxRow = gmath.ClampMin(maxRow, e.Row)
+	}
+	dx := (g.config.NumCols - 1 - maxCol - minCol) / 2
+	dy := (g.config.NumRows - 1 - maxRow - minRow) / 2
+	for i := range g.elems {
+		g.elems[i].Col += dx
+		g.elems[i].Row += dy
+	}
+}
+
+// newModule returns the module elements in the local coordinates (see place).
+//
+// Every module starts at the main row cell that has an incoming pipe
+// on its left and ends with a pipe that goes to the right.
+// The rows above and below the main row can be used too.
+func (g *levelGenerator) newModule(kind generatorModuleKind) []nativeElem {
+	switch kind {
+	case generatorMuxModule:
+		// An optional transform that is joined with the main row by a mux.
+		//
+		//	A@270 T     A
+		//	IF    P     MUX P
+		//
+		branch, straight := "special_angle_pipe", "pipe"
+		if g.rand.Bool() {
+			branch, straight = "angle_pipe", "special_pipe"
+		}
+		g.prevTransform = nil
+		return []nativeElem{
+			{Class: branch, Row: -1, Rotation: 270},
+			g.newTransform(1, -1),
+			{Class: "angle_pipe", Col: 2, Row: -1},
+			g.newIf(0, 0),
+			{Class: straight, Col: 1},
+			{Class: "elem_mux", Col: 2},
+			{Class: "pipe", Col: 3},
+		}
+
+	case generatorDiamondModule:
+		// An if/else with a transform in at least one of the branches.
+		//
+		//	A@270   T
+		//	IF      J
+		//	A@270~  T
+		//
+		top, bottom := "angle_pipe", "special_angle_pipe"
+		if g.rand.Bool() {
+			top, bottom = bottom, top
+		}
+		module := []nativeElem{
+			{Class: top, Row: -1, Rotation: 270},
+			g.newIf(0, 0),
+			{Class: bottom, Row: 1, Rotation: 270, FlipHorizontally: true},
+			{Class: "pipe_connect2", Col: 1},
+		}
+		g.prevTransform = nil
+		switch g.rand.IntRange(0, 2) {
+		case 0:
+			module = append(module, g.newTransform(1, -1), g.newTransform(1, 1))
+		case 1:
+			module = append(module,
+				g.newTransform(1, -1),
+				nativeElem{Class: "angle_pipe", Col: 1, Row: 1, Rotation: 180, FlipHorizontally: true})
+		default:
+			module = append(module,
+				nativeElem{Class: "angle_pipe", Col: 1, Row: -1},
+				g.newTransform(1, 1))
+		}
+		g.prevTransform = nil
+		return module
+
+	case generatorLoopModule:
+		// The countdown body is executed the countdown initial value times.
+		//
+		//	A@270 P   A
+		//	CD    SA  A@270~
+		//	A@180 T
+		//
+		g.prevTransform = nil
+		body := g.newTransform(1, 1)
+		g.prevTransform = nil
+		n := g.rand.IntRange(1, 3)
+		if info := g.tileset.ElemByClass(body.Class); n == 2 && info.Transform.op.inverse == info.Transform.Op {
+			// Two applications of a self-inverse transform are a no-op.
+			n = 3
+		}
+		return []nativeElem{
+			{Class: "angle_pipe", Row: -1, Rotation: 270},
+			{Class: "pipe", Col: 1, Row: -1},
+			{Class: "angle_pipe", Col: 2, Row: -1},
+			{Class: fmt.Sprintf("elem_countdown%d", n)},
+			{Class: "special_angle_pipe", Col: 1},
+			{Class: "angle_pipe", Col: 2, Rotation: 270, FlipHorizontally: true},
+			{Class: "angle_pipe", Row: 1, Rotation: 180},
+			body,
+		}
+
+	default:
+		return []nativeElem{
+			g.newTransform(0, 0),
+			{Class: "pipe", Col: 1},
+		}
+	}
+}
+
+func (g *levelGenerator) newTransform(col, row int) nativeElem {
+	var info *ElemInfo
+	for i := 0; i < maxGeneratorTransformRetries; i++ {
+		info = gmath.RandElem(&g.rand, g.transforms)
+		prev := g.prevTransform
+		if prev == nil {
+			break
+		}
+		// Avoid the same transform twice in a row as well as
+		// the transforms that revert the previous one.
+		if info != prev && !(info.Transform.Scope == prev.Transform.Scope && info.Transform.Op == prev.Transform.op.inverse) {
+			break
+		}
+	}
+	g.prevTransform = info
+	return nativeElem{Class: info.Class, Col: col, Row: row}
+}
+
+func (g *levelGenerator) newIf(col, row int) nativeElem {
+	class := "elem_if"
+	if g.rand.Bool() {
+		class = "elem_ifnot"
+	}
+	cond := gmath.RandElem(&g.rand, g.conds).gen(&g.rand)
+	return nativeElem{Class: class, Col: col, Row: row, If: &cond}
+}
@@ -0,0 +1,243 @@
+package leveldata
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/gmath"
+)
+
+var testGeneratorWords = strings.Fields(`
+	anchor badger candle dragon eagle falcon garden harbor island jungle
+	kettle lemon marble needle orange pepper quartz rabbit saddle tunnel
+	violet walnut yellow zipper bridge castle forest meadow river stone
+	cloud ember frost glade maple ocean pearl raven storm thorn
+`)
+
+func TestGenerateLevel(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	foundClasses := make(map[string]bool)
+	for difficulty := 1; difficulty <= MaxGeneratorDifficulty; difficulty++ {
+		for seed := int64(1); seed <= 30; seed++ {
+			config := GeneratorConfig{
+				Seed:       seed,
+				Difficulty: difficulty,
+				Words:      testGeneratorWords,
+			}
+			tmpl, err := GenerateLevel(tileset, config)
+			if err != nil {
+				t.Fatalf("difficulty=%d seed=%d: %v", difficulty, seed, err)
+			}
+			text, err := TemplateToText(tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tmpl2, err := GenerateLevel(tileset, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text2, err := TemplateToText(tmpl2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(text) != string(text2) {
+				t.Fatalf("difficulty=%d seed=%d: the same seed produced different levels:\n%s\n%s",
+					difficulty, seed, text, text2)
+			}
+
+			schema, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+			if err != nil {
+				t.Fatalf("difficulty=%d seed=%d: %v\n%s", difficulty, seed, err, text)
+			}
+			if schema.NumCols != DefaultNumSchemaCols || schema.NumRows != DefaultNumSchemaRows {
+				t.Fatalf("difficulty=%d seed=%d: unexpected grid size %dx%d", difficulty, seed, schema.NumCols, schema.NumRows)
+			}
+			if len(schema.Keywords) != GeneratorNumKeywords || schema.NumKeywords != 2 {
+				t.Fatalf("difficulty=%d seed=%d: unexpected keywords: %d of %v", difficulty, seed, schema.NumKeywords, schema.Keywords)
+			}
+			collisions, err := FindKeywordCollisions(schema, schema.Keywords)
+			if err != nil {
+				t.Fatalf("difficulty=%d seed=%d: %v\n%s", difficulty, seed, err, text)
+			}
+			if len(collisions) != 0 {
+				t.Fatalf("difficulty=%d seed=%d: found collisions: %v\n%s", difficulty, seed, collisions, text)
+			}
+			if diagnostics := Lint(schema); len(diagnostics) != 0 {
+				t.Fatalf("difficulty=%d seed=%d: %v\n%s", difficulty, seed, diagnostics, text)
+			}
+			for _, e := range schema.Elems {
+				foundClasses[e.TileClass] = true
+			}
+		}
+	}
+
+	for _, class := range []string{"elem_if", "elem_ifnot", "elem_mux", "pipe_connect2", "elem_countdown1", "elem_countdown3"} {
+		if !foundClasses[class] {
+			t.Errorf("no generated level uses %s", class)
+		}
+	}
+}
+
+func TestGenerateLevelGridSize(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tests := []struct {
+		cols int
+		rows int
+	}{
+		{5, 3},
+		{8, 3},
+		{12, 4},
+		{20, 12},
+		{64, 64},
+	}
+	for _, test := range tests {
+		for seed := int64(1); seed <= 10; seed++ {
+			tmpl, err := GenerateLevel(tileset, GeneratorConfig{
+				Seed:       seed,
+				Difficulty: MaxGeneratorDifficulty,
+				Words:      testGeneratorWords,
+				NumCols:    test.cols,
+				NumRows:    test.rows,
+			})
+			if err != nil {
+				t.Fatalf("%dx%d seed=%d: %v", test.cols, test.rows, seed, err)
+			}
+			if _, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build(); err != nil {
+				t.Fatalf("%dx%d seed=%d: %v", test.cols, test.rows, seed, err)
+			}
+		}
+	}
+}
+
+func TestGenerateLevelErrors(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tests := []struct {
+		config GeneratorConfig
+		err    string
+	}{
+		{
+			config: GeneratorConfig{Difficulty: 0, Words: testGeneratorWords},
+			err:    "invalid difficulty 0",
+		},
+		{
+			config: GeneratorConfig{Difficulty: MaxGeneratorDifficulty + 1, Words: testGeneratorWords},
+			err:    "invalid difficulty 6",
+		},
+		{
+			config: GeneratorConfig{Difficulty: 1, Words: testGeneratorWords, NumKeywords: 7},
+			err:    "invalid number of keywords 7",
+		},
+		{
+			config: GeneratorConfig{Difficulty: 1, Words: testGeneratorWords, NumCols: 4, NumRows: 8},
+			err:    "invalid grid size 4x8",
+		},
+		{
+			config: GeneratorConfig{Difficulty: 1, Words: []string{"apple", "Banana", "cherry", "apple", "verylongword", "dog", "egg"}},
+			err:    "expected at least 6 valid words, found 4",
+		},
+	}
+	for _, test := range tests {
+		_, err := GenerateLevel(tileset, test.config)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("unexpected error: %v\nexpected: %s", err, test.err)
+		}
+	}
+}
+
+func TestGenerateLevelCustomTileset(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	// A custom tileset can lack the transforms the generator needs.
+	newTileset := func(transforms ...string) *Tileset {
+		keep := make(map[string]bool, len(transforms))
+		for _, class := range transforms {
+			keep[class] = true
+		}
+		custom := *tileset
+		custom.elems = nil
+		for _, info := range tileset.Elems() {
+			if info.Kind == TransformElem && !keep[info.Class] {
+				continue
+			}
+			custom.elems = append(custom.elems, info)
+		}
+		return &custom
+	}
+
+	tests := []struct {
+		transforms []string
+		err        string
+	}{
+		{nil, "expected at least 3 transforms for difficulty 1, found 0"},
+		{[]string{"apply_add", "apply_sub"}, "expected at least 3 transforms for difficulty 1, found 2"},
+	}
+	for _, test := range tests {
+		config := GeneratorConfig{Seed: 1, Difficulty: 1, Words: testGeneratorWords}
+		_, err := GenerateLevel(newTileset(test.transforms...), config)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("%v: unexpected error: %v\nexpected: %s", test.transforms, err, test.err)
+		}
+	}
+
+	config := GeneratorConfig{Seed: 1, Difficulty: 1, Words: testGeneratorWords}
+	if _, err := GenerateLevel(newTileset("apply_add", "apply_sub", "apply_reverse"), config); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDailyGeneratorSeed(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		seed int64
+	}{
+		{time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC), 20230101},
+		{time.Date(2023, time.December, 31, 23, 59, 0, 0, time.UTC), 20231231},
+		{time.Date(2024, time.March, 1, 1, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)), 20240229},
+	}
+	for _, test := range tests {
+		if seed := DailyGeneratorSeed(test.date); seed != test.seed {
+			t.Fatalf("%s: seed is %d, expected %d", test.date, seed, test.seed)
+		}
+	}
+}
+
+func TestGeneratorWordList(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	data, err := os.ReadFile("../_assets/words.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := strings.Fields(string(data))
+	seen := make(map[string]bool, len(words))
+	for _, w := range words {
+		if !isValidInput(w) {
+			t.Fatalf("%q is not a valid input", w)
+		}
+		if seen[w] {
+			t.Fatalf("%q is duplicated", w)
+		}
+		seen[w] = true
+	}
+
+	// The daily puzzles can't be re-rolled, so every seed should work.
+	day := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		for difficulty := 1; difficulty <= MaxGeneratorDifficulty; difficulty++ {
+			config := GeneratorConfig{
+				Seed:       DailyGeneratorSeed(day),
+				Difficulty: difficulty,
+				Words:      words,
+			}
+			if _, err := GenerateLevel(tileset, config); err != nil {
+				t.Fatalf("%s difficulty=%d: %v", day.Format("2006-01-02"), difficulty, err)
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
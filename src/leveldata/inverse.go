@@ -0,0 +1,72 @@
+package leveldata
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDataDependentPath is reported when the execution path
+	// depends on the input letters, so it can't be followed backwards.
+	ErrDataDependentPath = errors.New("execution path depends on the input data")
+
+	// ErrLossyTransform is reported when a transform on the execution path
+	// maps several inputs to the same output, so it can't be reversed.
+	ErrLossyTransform = errors.New("lossy transform")
+)
+
+// Decode computes an input that is encoded as the given output
+// by running the schema program backwards.
+//
+// This is only possible if the execution path doesn't depend on the input letters
+// (conditions that only check the data length are permitted) and all
+// transforms on that path are invertible.
+// When decoding succeeds, the returned input is the only possible decoding.
+func Decode(schema *ComponentSchema, encoded string) (string, error) {
+	path, err := findTransformPath(schema, len(encoded))
+	if err != nil {
+		return "", err
+	}
+
+	data := []byte(encoded)
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].ExtraData.(*TransformElemExtra).applyInverse(data)
+	}
+	return string(data), nil
+}
+
+// findTransformPath returns all transforms the program executes for inputs of the specified length.
+func findTransformPath(schema *ComponentSchema, inputLen int) ([]*SchemaElem, error) {
+	input := make([]byte, inputLen)
+	for i := range input {
+		input[i] = 'a'
+	}
+
+	runner := NewSchemaRunner()
+	if err := runner.exec(schema, input); err != nil {
+		return nil, err
+	}
+
+	// The program is known to terminate, run it again step by step.
+	var path []*SchemaElem
+	runner.Reset(schema, input)
+	for {
+		e := runner.Current()
+		switch e.Kind {
+		case TransformElem:
+			if !e.ExtraData.(*TransformElemExtra).IsInvertible() {
+				return nil, fmt.Errorf("%w: %v: %s", ErrLossyTransform, e.Pos, e.TileClass)
+			}
+			path = append(path, e)
+		case IfElem:
+			if extra, ok := e.ExtraData.(*IfElemExtra); ok && !condKindByName[extra.CondKind].lengthOnly {
+				return nil, fmt.Errorf("%w: %v: %s %s", ErrDataDependentPath, e.Pos, e.TileClass, extra.CondKind)
+			}
+		}
+		if _, hasMore := runner.RunStep(); !hasMore {
+			break
+		}
+	}
+
+	return path, nil
+}
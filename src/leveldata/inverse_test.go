@@ -0,0 +1,85 @@
+package leveldata
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+func TestDecodeBuiltinLevels(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	filenames, err := filepath.Glob("../_assets/levels/*/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	numDecoded := 0
+	runner := NewSchemaRunner()
+	for _, filename := range filenames {
+		levelData, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := LoadLevelTemplate(tileset, levelData)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		schema, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		for _, k := range schema.Keywords {
+			encoded, err := runner.Exec(schema, k)
+			if err != nil {
+				t.Fatalf("%s: %q: %v", filename, k, err)
+			}
+			decoded, err := Decode(schema, encoded)
+			if err != nil {
+				if !errors.Is(err, ErrLossyTransform) && !errors.Is(err, ErrDataDependentPath) {
+					t.Fatalf("%s: %q: unexpected error: %v", filename, k, err)
+				}
+				continue
+			}
+			numDecoded++
+			// The decoding is unique, so it should be the keyword itself.
+			if decoded != k {
+				t.Fatalf("%s: %q is decoded as %q", filename, encoded, decoded)
+			}
+			output, err := runner.Exec(schema, decoded)
+			if err != nil {
+				t.Fatalf("%s: %q: %v", filename, decoded, err)
+			}
+			if output != encoded {
+				t.Fatalf("%s: %q is encoded as %q, expected %q", filename, decoded, output, encoded)
+			}
+		}
+	}
+	if numDecoded == 0 {
+		t.Fatal("no keywords were decoded")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tests := []struct {
+		level   string
+		encoded string
+		err     error
+	}{
+		{testLossyLevel, "zab", ErrLossyTransform},
+		{testLossyLevel, "", ErrLossyTransform},
+		{testBranchingLevel, "ac", ErrDataDependentPath},
+		{testBranchingLevel, "zz", ErrDataDependentPath},
+	}
+	for _, test := range tests {
+		schema := newTextSchema(t, tileset, test.level)
+		_, err := Decode(schema, test.encoded)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%q: unexpected error: %v\nexpected: %v", test.encoded, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package leveldata
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/quasilyte/gmath"
+)
+
+// KeywordChain describes how a chapter secret keyword is encoded by the chapter levels.
+//
+// Every level encodes the output of the previous one,
+// the last output is shown on the level select screen.
+type KeywordChain struct {
+	Chapter string
+	Keyword string
+	Links   []KeywordChainLink
+}
+
+type KeywordChainLink struct {
+	// Level is a level path, as specified in the campaign manifest.
+	Level string
+
+	Input  string
+	Output string
+}
+
+// Encoded returns the chain result.
+// For incomplete chains, it's the last successfully encoded value.
+func (c *KeywordChain) Encoded() string {
+	if len(c.Links) == 0 {
+		return c.Keyword
+	}
+	return c.Links[len(c.Links)-1].Output
+}
+
+// TraceKeywordChains encodes every story chapter secret keyword
+// using the chapter levels, one after another.
+//
+// The chain is well-formed if every intermediate value is a valid component input
+// (only lowercase letters, up to MaxInputLen), it never matches any of the level
+// keywords and its encoding is never the same as any of the level keyword encodings.
+// Otherwise, the secret keyword would be indistinguishable from the regular ones.
+//
+// All problems, including the level loading errors, are reported as
+// a DiagnosticList error; the chains are returned in both cases,
+// a chain stops at the first level that can't be loaded or can't encode it.
+func TraceKeywordChains(tileset *Tileset, c *Campaign, fsys fs.FS) ([]KeywordChain, error) {
+	var diagnostics DiagnosticList
+	errorf := func(format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Row:      -1,
+			Col:      -1,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	var chains []KeywordChain
+	runner := NewSchemaRunner()
+	for i := range c.Chapters {
+		chapter := &c.Chapters[i]
+		if chapter.IsBonus() {
+			continue
+		}
+		chain := KeywordChain{Chapter: chapter.Name, Keyword: chapter.Keyword}
+		if !isValidInput(chapter.Keyword) {
+			errorf("chapter %s: secret keyword %q is not a valid input", chapter.Name, chapter.Keyword)
+		}
+		input := chapter.Keyword
+		for _, levelPath := range chapter.Levels {
+			schema, err := loadCampaignLevel(tileset, fsys, levelPath)
+			if err != nil {
+				errorf("chapter %s: %s: %v", chapter.Name, levelPath, err)
+				break
+			}
+			output, err := runner.Exec(schema, input)
+			if err != nil {
+				errorf("chapter %s: %s: secret keyword %q: %v", chapter.Name, levelPath, input, err)
+				break
+			}
+			chain.Links = append(chain.Links, KeywordChainLink{
+				Level:  levelPath,
+				Input:  input,
+				Output: output,
+			})
+			for _, k := range schema.Keywords {
+				if k == input {
+					errorf("chapter %s: %s: secret keyword %q is also a level keyword", chapter.Name, levelPath, input)
+					continue
+				}
+				encoded, err := runner.Exec(schema, k)
+				if err != nil {
+					errorf("chapter %s: %s: keyword %q: %v", chapter.Name, levelPath, k, err)
+					continue
+				}
+				if encoded == output {
+					errorf("chapter %s: %s: secret keyword %q and level keyword %q are both encoded as %q",
+						chapter.Name, levelPath, input, k, output)
+				}
+			}
+			if !isValidInput(output) {
+				errorf("chapter %s: %s: secret keyword %q is encoded as %q, which is not a valid input",
+					chapter.Name, levelPath, input, output)
+				break
+			}
+			input = output
+		}
+		chains = append(chains, chain)
+	}
+
+	if len(diagnostics) != 0 {
+		return chains, diagnostics
+	}
+	return chains, nil
+}
+
+func loadCampaignLevel(tileset *Tileset, fsys fs.FS, levelPath string) (*ComponentSchema, error) {
+	levelData, err := fs.ReadFile(fsys, levelPath)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+}
@@ -0,0 +1,67 @@
+package leveldata
+
+// Lint reports the schema elements that are never executed.
+//
+// An element is unreachable if there is no path from the input to it.
+// The elem_if (and elem_ifnot) with fixed_cond only has one possible path.
+// Reachable elements that are not executed by any of the schema keywords
+// are reported too, as they're likely to be a red herring.
+//
+// Plain pipes are not reported as they're a part of some other element path.
+// All diagnostics have a SeverityWarning level.
+func Lint(schema *ComponentSchema) DiagnosticList {
+	reachable := make([]bool, len(schema.Elems))
+	walkReachable(schema.Entry, reachable)
+
+	// Non-terminating keywords are reported by the level validation,
+	// their partial paths are counted too.
+	covered := make([]bool, len(schema.Elems))
+	runner := NewSchemaRunner()
+	runner.Trace = func(e *SchemaElem, branch int) {
+		covered[e.ElemID] = true
+	}
+	for _, k := range schema.Keywords {
+		runner.Exec(schema, k)
+	}
+
+	var result DiagnosticList
+	for _, e := range schema.Elems {
+		switch e.Kind {
+		case SimplePipeElem, PipeConnect2Elem:
+			continue
+		}
+		switch {
+		case !reachable[e.ElemID]:
+			result = append(result, NewElemDiagnostic(SeverityWarning, e, "element is unreachable"))
+		case !covered[e.ElemID] && len(schema.Keywords) != 0:
+			result = append(result, NewElemDiagnostic(SeverityWarning, e, "element is not executed by any keyword"))
+		}
+	}
+	return result
+}
+
+func walkReachable(e *SchemaElem, visited []bool) {
+	if visited[e.ElemID] {
+		return
+	}
+	visited[e.ElemID] = true
+	for _, next := range staticSuccessors(e) {
+		walkReachable(next, visited)
+	}
+}
+
+// staticSuccessors returns the elements the signal can go to after e.
+func staticSuccessors(e *SchemaElem) []*SchemaElem {
+	extra, ok := e.ExtraData.(*IfElemExtra)
+	if !ok || extra.CondKind != "fixed_cond" {
+		return e.Next
+	}
+	cond := extra.IntArg == 1
+	if e.TileClass == "elem_ifnot" {
+		cond = !cond
+	}
+	if cond {
+		return e.Next[:1]
+	}
+	return e.Next[1:2]
+}
@@ -0,0 +1,52 @@
+package leveldata
+
+import (
+	"os"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+func TestLint(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	levelData, err := os.ReadFile("../_assets/levels/story/fixed_cond.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadLevelTemplate(tileset, levelData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unreachable := map[string]bool{}
+	for _, d := range Lint(schema) {
+		if d.Message == "element is unreachable" {
+			unreachable[d.Class] = true
+		}
+	}
+	for _, class := range []string{"apply_hardshift_left", "apply_rotate_left"} {
+		if !unreachable[class] {
+			t.Errorf("%s is not reported as unreachable", class)
+		}
+	}
+
+	// Without keywords, only the static analysis is performed.
+	schema.Keywords = nil
+	for _, d := range Lint(schema) {
+		if d.Message != "element is unreachable" {
+			t.Errorf("unexpected diagnostic: %v", d)
+		}
+	}
+
+	// A linear schema has no dead elements.
+	linear := newLinearSchema(t, tileset, "apply_add", "apply_reverse")
+	linear.Keywords = []string{"abc"}
+	if diagnostics := Lint(linear); len(diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics:\n%v", diagnostics)
+	}
+}
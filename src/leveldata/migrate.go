@@ -0,0 +1,300 @@
+package leveldata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/quasilyte/gmath"
+)
+
+// LevelFormatVersion is the latest level format version.
+//
+// Tiled maps store it in the settings format_version property,
+// the maps without that property have version 0.
+// The schemas.tsj settings tile has the latest version as
+// the property default, keep them in sync.
+// Native levels store it in the version field.
+//
+// The version should be increased every time the meaning of
+// the existing level data changes; the older levels are upgraded
+// by the matching levelMigrations entry.
+const LevelFormatVersion = 1
+
+type levelMigration struct {
+	// version is a format version this migration upgrades the level to.
+	version int
+
+	// tilemap and native upgrade the level data of the corresponding format.
+	// A nil func means that there is nothing to change.
+	tilemap func(tileset *Tileset, m *tiledMapData) error
+	native  func(tileset *Tileset, level *nativeLevel) error
+}
+
+// levelMigrations are ordered by the version.
+var levelMigrations = []levelMigration{
+	{version: 1, tilemap: migrateVerticalFlips},
+}
+
+// MigrateLevelData upgrades the level data to the LevelFormatVersion.
+//
+// It returns the upgraded level data along with its original format version.
+// If the level is already up to date, levelData is returned as is.
+// Otherwise the result has its format version set to LevelFormatVersion,
+// even if the migrations had nothing else to change.
+func MigrateLevelData(tileset *Tileset, levelData []byte) ([]byte, int, error) {
+	if IsNativeLevel(levelData) {
+		return migrateNativeLevel(tileset, levelData)
+	}
+	return migrateTilemap(tileset, levelData)
+}
+
+func checkLevelFormatVersion(version int) error {
+	if version > LevelFormatVersion {
+		return fmt.Errorf("unsupported format version %d (the latest is %d)", version, LevelFormatVersion)
+	}
+	return nil
+}
+
+func migrateNativeLevel(tileset *Tileset, levelData []byte) ([]byte, int, error) {
+	var level nativeLevel
+	if err := json.Unmarshal(levelData, &level); err != nil {
+		return nil, 0, err
+	}
+	version := level.Version
+	if err := checkLevelFormatVersion(version); err != nil {
+		return nil, version, err
+	}
+	if version < 1 {
+		return nil, version, fmt.Errorf("invalid format version %d", version)
+	}
+	if version == LevelFormatVersion {
+		return levelData, version, nil
+	}
+	for _, m := range levelMigrations {
+		if m.version <= version || m.native == nil {
+			continue
+		}
+		if err := m.native(tileset, &level); err != nil {
+			return nil, version, fmt.Errorf("migrate to version %d: %w", m.version, err)
+		}
+	}
+	level.Version = LevelFormatVersion
+	result, err := json.MarshalIndent(level, "", "  ")
+	return result, version, err
+}
+
+func migrateTilemap(tileset *Tileset, levelData []byte) ([]byte, int, error) {
+	m, err := decodeTiledMapData(levelData)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	settings := findTiledObject(tileset, m, "settings")
+	if settings == nil {
+		return nil, 0, errors.New("a map should have a settings object")
+	}
+	version := 0
+	for _, p := range settings.Properties {
+		if p.Name != "format_version" {
+			continue
+		}
+		v, ok := p.Value.(float64)
+		if !ok {
+			return nil, 0, errors.New("settings.format_version property should be an int")
+		}
+		version = int(v)
+	}
+	if err := checkLevelFormatVersion(version); err != nil {
+		return nil, version, err
+	}
+	if version == LevelFormatVersion {
+		return levelData, version, nil
+	}
+
+	for _, migration := range levelMigrations {
+		if migration.version <= version || migration.tilemap == nil {
+			continue
+		}
+		if err := migration.tilemap(tileset, m); err != nil {
+			return nil, version, fmt.Errorf("migrate to version %d: %w", migration.version, err)
+		}
+	}
+	setTiledProp(settings, tiledIntProp("format_version", LevelFormatVersion))
+
+	result, err := m.encode()
+	return result, version, err
+}
+
+// migrateVerticalFlips rewrites the vertically flipped angle pipes.
+//
+// The vertical flipping is equivalent to the horizontal flipping
+// followed by a 180 degrees rotation.
+func migrateVerticalFlips(tileset *Tileset, m *tiledMapData) error {
+	for i := range m.objects {
+		o := &m.objects[i].tiledObjectJSON
+		if o.GID&tiledFlippedVerticallyFlag == 0 {
+			continue
+		}
+		t := tileset.TileByID(int(o.GID&^tiledFlagsMask) - m.firstGID)
+		if t == nil || (t.Class != "angle_pipe" && t.Class != "special_angle_pipe") {
+			continue
+		}
+		pos := gmath.Vec{X: o.X, Y: o.Y}
+		rotation := int(o.Rotation)
+		center, ok := tiledObjectCenter(tileset, pos, rotation)
+		if !ok || float64(rotation) != o.Rotation {
+			return fmt.Errorf("%v: unsupported rotation angle %v", pos, o.Rotation)
+		}
+		rotation = (rotation + 180) % 360
+		pos, _ = tiledObjectPos(tileset, center, rotation)
+		o.Rotation = float64(rotation)
+		o.X = pos.X
+		o.Y = pos.Y
+		o.GID &^= tiledFlippedVerticallyFlag
+		o.GID ^= tiledFlippedHorizontallyFlag
+	}
+	return nil
+}
+
+// tiledMapData is a Tiled map that is decoded for the migrations.
+//
+// Only the first layer objects are decoded, the rest of the map is kept as is.
+// When the map is encoded back, only the changed object fields are replaced,
+// so the fields that tiledObjectJSON doesn't describe are preserved.
+type tiledMapData struct {
+	fields   map[string]json.RawMessage
+	layers   []map[string]json.RawMessage
+	firstGID int
+	objects  []tiledObjectData
+}
+
+type tiledObjectData struct {
+	tiledObjectJSON
+
+	fields map[string]json.RawMessage
+	orig   tiledObjectJSON
+}
+
+func decodeTiledMapData(data []byte) (*tiledMapData, error) {
+	m := &tiledMapData{}
+	if err := json.Unmarshal(data, &m.fields); err != nil {
+		return nil, err
+	}
+	var tilesets []tiledTilesetRefJSON
+	if err := unmarshalTiledField(m.fields, "tilesets", &tilesets); err != nil {
+		return nil, err
+	}
+	if err := unmarshalTiledField(m.fields, "layers", &m.layers); err != nil {
+		return nil, err
+	}
+	if len(tilesets) == 0 || len(m.layers) == 0 {
+		return nil, errors.New("a map should have at least one tileset and layer")
+	}
+	m.firstGID = tilesets[0].FirstGID
+
+	var objects []json.RawMessage
+	if err := unmarshalTiledField(m.layers[0], "objects", &objects); err != nil {
+		return nil, err
+	}
+	m.objects = make([]tiledObjectData, len(objects))
+	for i, objectData := range objects {
+		o := &m.objects[i]
+		if err := json.Unmarshal(objectData, &o.fields); err != nil {
+			return nil, err
+		}
+		// The orig copy shouldn't share the properties slice.
+		if err := json.Unmarshal(objectData, &o.tiledObjectJSON); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(objectData, &o.orig); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func unmarshalTiledField(fields map[string]json.RawMessage, name string, dst any) error {
+	data, ok := fields[name]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func (m *tiledMapData) encode() ([]byte, error) {
+	objects := make([]map[string]json.RawMessage, len(m.objects))
+	for i := range m.objects {
+		o := &m.objects[i]
+		patches := []struct {
+			name    string
+			value   any
+			changed bool
+		}{
+			{"gid", o.GID, o.GID != o.orig.GID},
+			{"properties", o.Properties, !reflect.DeepEqual(o.Properties, o.orig.Properties)},
+			{"rotation", o.Rotation, o.Rotation != o.orig.Rotation},
+			{"x", o.X, o.X != o.orig.X},
+			{"y", o.Y, o.Y != o.orig.Y},
+		}
+		for _, p := range patches {
+			if !p.changed {
+				continue
+			}
+			data, err := json.Marshal(p.value)
+			if err != nil {
+				return nil, err
+			}
+			o.fields[p.name] = data
+		}
+		objects[i] = o.fields
+	}
+
+	if err := setTiledField(m.layers[0], "objects", objects); err != nil {
+		return nil, err
+	}
+	if err := setTiledField(m.fields, "layers", m.layers); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(m.fields, "", " ")
+}
+
+func setTiledField(fields map[string]json.RawMessage, name string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	fields[name] = data
+	return nil
+}
+
+func findTiledObject(tileset *Tileset, m *tiledMapData, class string) *tiledObjectJSON {
+	for i := range m.objects {
+		o := &m.objects[i].tiledObjectJSON
+		t := tileset.TileByID(int(o.GID&^tiledFlagsMask) - m.firstGID)
+		if t != nil && t.Class == class {
+			return o
+		}
+	}
+	return nil
+}
+
+// setTiledProp adds or replaces the object property.
+// Like Tiled, it keeps the properties sorted by their names.
+func setTiledProp(o *tiledObjectJSON, prop tiledPropertyJSON) {
+	for i := range o.Properties {
+		if o.Properties[i].Name == prop.Name {
+			o.Properties[i] = prop
+			return
+		}
+	}
+	o.Properties = append(o.Properties, prop)
+	sort.SliceStable(o.Properties, func(i, j int) bool {
+		return o.Properties[i].Name < o.Properties[j].Name
+	})
+}
@@ -0,0 +1,228 @@
+package leveldata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+func TestMigrateVerticalFlips(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	filenames, err := filepath.Glob("../_assets/levels/*/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	numFlipped := 0
+	for _, filename := range filenames {
+		levelData, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := LoadLevelTemplate(tileset, levelData)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		want, err := TemplateToText(tmpl)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+
+		// Encode every angle pipe in the legacy way, with a vertical flip.
+		m, err := decodeTiledMapData(levelData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range m.objects {
+			o := &m.objects[i].tiledObjectJSON
+			class := tileset.TileByID(int(o.GID&^tiledFlagsMask) - 1).Class
+			if class != "angle_pipe" && class != "special_angle_pipe" {
+				continue
+			}
+			center, _ := tiledObjectCenter(tileset, gmath.Vec{X: o.X, Y: o.Y}, int(o.Rotation))
+			rotation := (int(o.Rotation) + 180) % 360
+			pos, _ := tiledObjectPos(tileset, center, rotation)
+			o.Rotation = float64(rotation)
+			o.X = pos.X
+			o.Y = pos.Y
+			o.GID |= tiledFlippedVerticallyFlag
+			o.GID ^= tiledFlippedHorizontallyFlag
+			numFlipped++
+		}
+		setTiledProp(findTiledObject(tileset, m, "settings"), tiledIntProp("format_version", 0))
+		legacyData, err := m.encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		migrated, version, err := MigrateLevelData(tileset, legacyData)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		if version != 0 {
+			t.Fatalf("%s: expected version 0, found %d", filename, version)
+		}
+		migratedTmpl, err := LoadLevelTemplate(tileset, migrated)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		have, err := TemplateToText(migratedTmpl)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		if string(have) != string(want) {
+			t.Fatalf("%s: migration mismatch:\n%s\nvs\n%s", filename, want, have)
+		}
+
+		// The migrated level is up to date.
+		migrated2, version, err := MigrateLevelData(tileset, migrated)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		if version != LevelFormatVersion || !bytes.Equal(migrated, migrated2) {
+			t.Fatalf("%s: the migrated level is not up to date (version %d)", filename, version)
+		}
+	}
+	if numFlipped == 0 {
+		t.Fatal("found no angle pipes")
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tmpl := newLinearTemplate(tileset, "pipe")
+	tmpl.Keywords = []string{"a"}
+	tiledData, err := TemplateToTilemap(tmpl, "../../schemas.tsj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := decodeTiledMapData(tiledData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setTiledProp(findTiledObject(tileset, m, "settings"), tiledIntProp("format_version", 100))
+	newerData, err := m.encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		level string
+		err   string
+	}{
+		{string(newerData), "unsupported format version 100 (the latest is 1)"},
+		{`{"format": "decipherism-level", "version": 100, "keywords": ["a"]}`, "unsupported format version 100"},
+		{`{"format": "decipherism-level", "version": 0, "keywords": ["a"]}`, "invalid format version 0"},
+		{`{"height": 8, "width": 12, "tilesets": [{"firstgid": 1, "source": "schemas.tsj"}], "layers": [{"type": "objectgroup", "objects": [
+			{"gid": 21, "x": 0, "y": 864, "properties": [{"name": "format_version", "type": "int", "value": 1}, {"name": "keywords", "type": "string", "value": "a"}]},
+			{"gid": 999, "x": 0, "y": 96}
+		]}]}`, "unknown tile gid 999"},
+	}
+
+	for _, test := range tests {
+		_, err := LoadLevelTemplate(tileset, []byte(test.level))
+		if err == nil {
+			t.Errorf("%q: expected an error", test.level)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: unexpected error: %v", test.level, err)
+		}
+	}
+}
+
+func TestMigrateKeepsMapData(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	// The map data that is not used by the game should survive the migration.
+	const levelTemplate = `{"compressionlevel": -1,
+		"editorsettings": {"export": {"target": "."}},
+		"height": 8,
+		"layers": [
+			{"draworder": "topdown", "id": 2, "name": "scheme", "opacity": 0.5, "type": "objectgroup", "visible": true, "x": 0, "y": 0,
+				"objects": [
+					{"gid": %d, "height": 96, "id": 1, "name": "", "rotation": 0, "type": "legacy", "visible": true, "width": 96, "x": 0, "y": 96},
+					{"gid": 21, "height": 96, "id": 2, "name": "", "rotation": 0, "visible": true, "width": 96, "x": 0, "y": 864,
+						"properties": [{"name": "keywords", "type": "string", "value": "a"}]},
+					{"gid": 57, "height": 96, "id": 3, "name": "", "rotation": 12.5, "visible": true, "width": 96, "x": 96, "y": 864,
+						"properties": [{"name": "text", "type": "string", "value": "hint"}]}
+				]},
+			{"data": [0, 0], "height": 1, "id": 3, "name": "tiles", "opacity": 1, "type": "tilelayer", "visible": true, "width": 2, "x": 0, "y": 0}
+		],
+		"properties": [{"name": "author", "type": "string", "value": "me"}],
+		"tilesets": [{"firstgid": 1, "source": "../../schemas.tsj"}],
+		"width": 12
+	}`
+	const anglePipeGID = 8
+
+	for _, flipped := range []bool{false, true} {
+		gid := anglePipeGID
+		if flipped {
+			gid |= tiledFlippedVerticallyFlag
+		}
+		levelData := []byte(fmt.Sprintf(levelTemplate, gid))
+		migrated, version, err := MigrateLevelData(tileset, levelData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != 0 {
+			t.Fatalf("expected version 0, found %d", version)
+		}
+		var have, want map[string]any
+		if err := json.Unmarshal(migrated, &have); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(levelData, &want); err != nil {
+			t.Fatal(err)
+		}
+		// The only expected changes are the flipped angle pipe and the format version.
+		// The format version is set even if there is nothing else to migrate.
+		objects := want["layers"].([]any)[0].(map[string]any)["objects"].([]any)
+		if flipped {
+			pipe := objects[0].(map[string]any)
+			pipe["gid"] = float64(anglePipeGID | tiledFlippedHorizontallyFlag)
+			pipe["rotation"] = float64(180)
+			pipe["x"] = float64(96)
+			pipe["y"] = float64(0)
+		}
+		settings := objects[1].(map[string]any)
+		settings["properties"] = []any{
+			map[string]any{"name": "format_version", "type": "int", "value": float64(LevelFormatVersion)},
+			map[string]any{"name": "keywords", "type": "string", "value": "a"},
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Fatalf("flipped=%v: unexpected migration result:\n%s", flipped, migrated)
+		}
+	}
+}
+
+func TestTilesetFormatVersion(t *testing.T) {
+	data, err := os.ReadFile("../_assets/schemas.tsj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw tilesetJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	// The new Tiled maps should get the latest version by default.
+	for _, tile := range raw.Tiles {
+		if tile.Class != "settings" {
+			continue
+		}
+		for _, p := range tile.Properties {
+			if p.Name == "format_version" && p.Value == float64(LevelFormatVersion) {
+				return
+			}
+		}
+	}
+	t.Fatalf("the settings tile format_version default should be %d", LevelFormatVersion)
+}
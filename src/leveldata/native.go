@@ -0,0 +1,259 @@
+package leveldata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/quasilyte/gmath"
+)
+
+// NativeFormatName is a "format" field value of the native level files.
+const NativeFormatName = "decipherism-level"
+
+// nativeLevel is a native level file contents.
+//
+// Unlike Tiled maps, it doesn't depend on the tileset gids:
+// elements are referenced by their class and placed by their grid coordinates.
+type nativeLevel struct {
+	Format string `json:"format"`
+
+	// Version is a LevelFormatVersion this level is encoded with.
+	Version int `json:"version"`
+
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+
+	NumKeywords int      `json:"num_keywords"`
+	Keywords    []string `json:"keywords"`
+
+	Elems []nativeElem `json:"elems"`
+	Hints []nativeHint `json:"hints,omitempty"`
+}
+
+type nativeElem struct {
+	Class string `json:"class"`
+	Col   int    `json:"col"`
+	Row   int    `json:"row"`
+
+	// Rotation is a clockwise rotation in degrees: 0, 90, 180 or 270.
+	Rotation int `json:"rotation,omitempty"`
+
+	// FlipHorizontally is only allowed for the angle pipes.
+	FlipHorizontally bool `json:"flip_horizontally,omitempty"`
+
+	If        *nativeIfExtra        `json:"if,omitempty"`
+	Transform *nativeTransformExtra `json:"transform,omitempty"`
+}
+
+type nativeIfExtra struct {
+	CondKind  string `json:"cond_kind"`
+	StringArg string `json:"string_arg,omitempty"`
+	IntArg    int    `json:"int_arg,omitempty"`
+}
+
+type nativeTransformExtra struct {
+	Op        string `json:"op"`
+	Scope     string `json:"scope,omitempty"`
+	RangeFrom int    `json:"range_from,omitempty"`
+	RangeTo   int    `json:"range_to,omitempty"`
+}
+
+type nativeHint struct {
+	Text string `json:"text"`
+
+	// Col and Row can be fractional.
+	Col float64 `json:"col"`
+	Row float64 `json:"row"`
+}
+
+// IsNativeLevel reports whether levelData is a native level file.
+func IsNativeLevel(levelData []byte) bool {
+	var header struct {
+		Format string `json:"format"`
+	}
+	if err := json.Unmarshal(levelData, &header); err != nil {
+		return false
+	}
+	return header.Format == NativeFormatName
+}
+
+// LoadNativeTemplate decodes a schema template from the native level format.
+func LoadNativeTemplate(tileset *Tileset, levelData []byte) (*SchemaTemplate, error) {
+	var level nativeLevel
+	if err := json.Unmarshal(levelData, &level); err != nil {
+		return nil, err
+	}
+	if level.Format != NativeFormatName {
+		return nil, fmt.Errorf("unexpected format %q", level.Format)
+	}
+	if err := checkLevelFormatVersion(level.Version); err != nil {
+		return nil, err
+	}
+	if level.Version != LevelFormatVersion {
+		return nil, fmt.Errorf("format version %d is outdated, the level needs to be migrated", level.Version)
+	}
+	if len(level.Keywords) == 0 {
+		return nil, errors.New("keywords list is empty")
+	}
+
+	result := &SchemaTemplate{
+		Tileset:     tileset,
+		NumCols:     level.Cols,
+		NumRows:     level.Rows,
+		NumKeywords: level.NumKeywords,
+		Keywords:    level.Keywords,
+	}
+
+	for _, e := range level.Elems {
+		elem, err := newNativeTemplateElem(tileset, e)
+		if err != nil {
+			return nil, err
+		}
+		result.Elems = append(result.Elems, elem)
+	}
+
+	for _, h := range level.Hints {
+		result.Hints = append(result.Hints, SchemaHintTemplate{
+			Text: h.Text,
+			Pos:  gmath.Vec{X: h.Col * tileset.TileWidth, Y: h.Row * tileset.TileHeight},
+		})
+	}
+
+	return result, nil
+}
+
+func newNativeTemplateElem(tileset *Tileset, e nativeElem) (SchemaTemplateElem, error) {
+	info := tileset.ElemByClass(e.Class)
+	if info == nil {
+		return SchemaTemplateElem{}, fmt.Errorf("%d,%d: unknown element class %q", e.Col, e.Row, e.Class)
+	}
+	elem := SchemaTemplateElem{
+		Class:   info.Class,
+		ClassID: info.TileID,
+		Pos: gmath.Vec{
+			X: (float64(e.Col) + 0.5) * tileset.TileWidth,
+			Y: (float64(e.Row) + 0.5) * tileset.TileHeight,
+		},
+	}
+	if err := initNativeElem(tileset, &elem, e); err != nil {
+		return SchemaTemplateElem{}, fmt.Errorf("%d,%d: %s: %w", e.Col, e.Row, e.Class, err)
+	}
+	return elem, nil
+}
+
+func initNativeElem(tileset *Tileset, elem *SchemaTemplateElem, e nativeElem) error {
+	switch e.Rotation {
+	case 0, 90, 180, 270:
+		elem.Rotation = gmath.DegToRad(float64(e.Rotation))
+	default:
+		return fmt.Errorf("invalid rotation %d, expected 0, 90, 180 or 270", e.Rotation)
+	}
+
+	switch e.Class {
+	case "angle_pipe", "special_angle_pipe":
+		elem.ExtraData = &AngleElemExtra{FlipHorizontally: e.FlipHorizontally}
+	default:
+		if e.FlipHorizontally {
+			return errors.New("only angle pipes can be flipped")
+		}
+	}
+
+	props := mapProps{}
+	if e.If != nil {
+		props["cond_kind"] = e.If.CondKind
+		props["string_arg"] = e.If.StringArg
+		props["int_arg"] = strconv.Itoa(e.If.IntArg)
+	}
+	if e.Transform != nil {
+		props["op"] = e.Transform.Op
+		props["scope"] = e.Transform.Scope
+		props["range_from"] = strconv.Itoa(e.Transform.RangeFrom)
+		props["range_to"] = strconv.Itoa(e.Transform.RangeTo)
+	}
+	return initElemExtra(tileset, elem, props)
+}
+
+// TemplateToNative encodes the schema template in the native level format.
+func TemplateToNative(t *SchemaTemplate) ([]byte, error) {
+	level := nativeLevel{
+		Format:      NativeFormatName,
+		Version:     LevelFormatVersion,
+		Cols:        t.NumCols,
+		Rows:        t.NumRows,
+		NumKeywords: t.NumKeywords,
+		Keywords:    t.Keywords,
+	}
+	if level.Cols == 0 && level.Rows == 0 {
+		level.Cols = DefaultNumSchemaCols
+		level.Rows = DefaultNumSchemaRows
+	}
+
+	for _, e := range t.Elems {
+		col, row, err := templateElemCell(t, e)
+		if err != nil {
+			return nil, err
+		}
+		elem := nativeElem{
+			Class:    e.Class,
+			Col:      col,
+			Row:      row,
+			Rotation: rotationDegrees(e.Rotation),
+		}
+		if elem.Rotation%90 != 0 {
+			return nil, fmt.Errorf("%v: %s: unsupported rotation angle %d", e.Pos, e.Class, elem.Rotation)
+		}
+		switch extra := e.ExtraData.(type) {
+		case *AngleElemExtra:
+			elem.FlipHorizontally = extra.FlipHorizontally
+		case *IfElemExtra:
+			elem.If = &nativeIfExtra{
+				CondKind:  extra.CondKind,
+				StringArg: extra.StringArg,
+				IntArg:    extra.IntArg,
+			}
+		case *TransformElemExtra:
+			if info := t.Tileset.ElemByClass(e.Class); info != nil && info.IsConfigurable() {
+				elem.Transform = &nativeTransformExtra{
+					Op:        extra.Op,
+					Scope:     extra.Scope,
+					RangeFrom: extra.RangeFrom,
+					RangeTo:   extra.RangeTo,
+				}
+			}
+		}
+		level.Elems = append(level.Elems, elem)
+	}
+
+	for _, h := range t.Hints {
+		level.Hints = append(level.Hints, nativeHint{
+			Text: h.Text,
+			Col:  h.Pos.X / t.Tileset.TileWidth,
+			Row:  h.Pos.Y / t.Tileset.TileHeight,
+		})
+	}
+
+	return json.MarshalIndent(level, "", "  ")
+}
+
+// templateElemCell returns the grid coordinates of a grid-aligned element.
+func templateElemCell(t *SchemaTemplate, e SchemaTemplateElem) (int, int, error) {
+	col := int(math.Floor(e.Pos.X / t.Tileset.TileWidth))
+	row := int(math.Floor(e.Pos.Y / t.Tileset.TileHeight))
+	center := gmath.Vec{
+		X: (float64(col) + 0.5) * t.Tileset.TileWidth,
+		Y: (float64(row) + 0.5) * t.Tileset.TileHeight,
+	}
+	if !e.Pos.EqualApprox(center) {
+		return 0, 0, fmt.Errorf("%v: %s: element is not aligned to the grid", e.Pos, e.Class)
+	}
+	return col, row, nil
+}
+
+// rotationDegrees converts the element rotation to [0, 360) degrees.
+func rotationDegrees(r gmath.Rad) int {
+	degrees := int(math.Round(float64(r) * 180 / math.Pi))
+	return (degrees%360 + 360) % 360
+}
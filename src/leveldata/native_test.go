@@ -0,0 +1,96 @@
+package leveldata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNativeTemplateErrors(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tests := []struct {
+		level string
+		err   string
+	}{
+		{`{"format": "decipherism-level", "version": 100, "keywords": ["a"]}`, "unsupported format version 100"},
+		{`{"format": "decipherism-level", "version": 1}`, "keywords list is empty"},
+		{`{"format": "decipherism-level", "version": 1, "keywords": ["a"], "elems": [{"class": "foo"}]}`, `0,0: unknown element class "foo"`},
+		{`{"format": "decipherism-level", "version": 1, "keywords": ["a"], "elems": [{"class": "pipe", "rotation": 45}]}`, "0,0: pipe: invalid rotation 45"},
+		{`{"format": "decipherism-level", "version": 1, "keywords": ["a"], "elems": [{"class": "pipe", "flip_horizontally": true}]}`, "only angle pipes can be flipped"},
+		{`{"format": "decipherism-level", "version": 1, "keywords": ["a"], "elems": [{"class": "elem_if"}]}`, "cond_kind property is empty"},
+	}
+
+	for _, test := range tests {
+		_, err := LoadLevelTemplate(tileset, []byte(test.level))
+		if err == nil {
+			t.Errorf("%q: expected an error", test.level)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: unexpected error: %v", test.level, err)
+		}
+	}
+}
+
+func TestNativeTemplateLevels(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	filenames, err := filepath.Glob("../_assets/levels/*/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, filename := range filenames {
+		levelData, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := LoadLevelTemplate(tileset, levelData)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		// The text format is used as a canonical template form.
+		want, err := TemplateToText(tmpl)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+
+		nativeData, err := TemplateToNative(tmpl)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		if !IsNativeLevel(nativeData) {
+			t.Fatalf("%s: native level is not recognized", filename)
+		}
+		fromNative, err := LoadLevelTemplate(tileset, nativeData)
+		if err != nil {
+			t.Fatalf("%s: load native:\n%s\n%v", filename, nativeData, err)
+		}
+
+		tiledData, err := TemplateToTilemap(fromNative, "../../schemas.tsj")
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		if IsNativeLevel(tiledData) {
+			t.Fatalf("%s: tiled level is recognized as native", filename)
+		}
+		fromTiled, err := LoadLevelTemplate(tileset, tiledData)
+		if err != nil {
+			t.Fatalf("%s: load tiled:\n%s\n%v", filename, tiledData, err)
+		}
+
+		for _, converted := range []*SchemaTemplate{fromNative, fromTiled} {
+			have, err := TemplateToText(converted)
+			if err != nil {
+				t.Fatalf("%s: %v", filename, err)
+			}
+			if string(have) != string(want) {
+				t.Fatalf("%s: round trip mismatch:\n%s\nvs\n%s", filename, want, have)
+			}
+		}
+		if err := ValidateLevelData(tileset, nativeData); err != nil {
+			t.Fatalf("%s: validate native: %v", filename, err)
+		}
+	}
+}
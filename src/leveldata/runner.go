@@ -0,0 +1,295 @@
+package leveldata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/quasilyte/gmath"
+)
+
+// DefaultMaxSteps is a step limit that is used when SchemaRunner.MaxSteps is 0.
+const DefaultMaxSteps = 100000
+
+// ErrNonTerminating is reported when a program never reaches its output.
+var ErrNonTerminating = errors.New("program never reaches the output")
+
+// SchemaRunner executes a component schema.
+//
+// It can run the whole program at once via Exec or
+// advance it one element at a time via RunStep.
+type SchemaRunner struct {
+	// MaxSteps limits the number of steps Exec can make.
+	// Zero value means DefaultMaxSteps.
+	MaxSteps int
+
+	// Trace is called for every element executed by RunStep, including the output.
+	// It's optional.
+	Trace TraceFunc
+
+	schema   *ComponentSchema
+	current  *SchemaElem
+	input    []byte
+	data     []byte
+	counters []uint8 // Indexed by ElemID
+	lastCond bool
+
+	// The RunStepChecked loop detection state.
+	steps      int
+	power      int
+	cycleLen   int
+	checkpoint runnerState
+}
+
+// runnerState is a snapshot of everything that affects the program execution.
+// If the runner reaches the same state twice, it will loop forever.
+type runnerState struct {
+	current  *SchemaElem
+	data     []byte
+	counters []uint8
+	lastCond bool
+}
+
+// TraceFunc is a SchemaRunner execution hook.
+//
+// For the IfElem kinds, branch is an index of the taken Next element:
+// 0 for the special_ pipe branch and 1 for the other one.
+// For the other elements, branch is -1.
+type TraceFunc func(e *SchemaElem, branch int)
+
+func NewSchemaRunner() *SchemaRunner {
+	return &SchemaRunner{
+		data: make([]byte, 0, 16),
+	}
+}
+
+// Exec runs the schema program for the k input and returns its output.
+//
+// If the program gets stuck in a loop or exceeds the step limit,
+// an error wrapping ErrNonTerminating is returned.
+func (r *SchemaRunner) Exec(s *ComponentSchema, k string) (string, error) {
+	if err := r.exec(s, []byte(k)); err != nil {
+		return "", err
+	}
+	return string(r.data), nil
+}
+
+func (r *SchemaRunner) exec(s *ComponentSchema, input []byte) error {
+	r.Reset(s, input)
+	for {
+		_, hasMore, err := r.RunStepChecked()
+		if err != nil {
+			return err
+		}
+		if !hasMore {
+			return nil
+		}
+	}
+}
+
+// RunStepChecked is like RunStep, but it also enforces the MaxSteps limit
+// and detects the loops the same way Exec does.
+//
+// If the program gets stuck in a loop or exceeds the step limit,
+// an error wrapping ErrNonTerminating is returned.
+func (r *SchemaRunner) RunStepChecked() (gmath.Vec, bool, error) {
+	maxSteps := r.MaxSteps
+	if maxSteps == 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	if r.steps >= maxSteps {
+		return gmath.Vec{}, false, fmt.Errorf("%w: step limit (%d) exceeded", ErrNonTerminating, maxSteps)
+	}
+	r.steps++
+
+	dst, hasMore := r.RunStep()
+	if !hasMore {
+		return dst, false, nil
+	}
+
+	// A Brent's cycle detection: compare the current state with a checkpoint
+	// that is moved forward every power-of-two steps.
+	r.cycleLen++
+	if r.matchCheckpoint() {
+		return dst, false, fmt.Errorf("%w: a loop detected after %d steps", ErrNonTerminating, r.steps)
+	}
+	if r.cycleLen == r.power {
+		r.saveCheckpoint()
+		r.power *= 2
+		r.cycleLen = 0
+	}
+	return dst, true, nil
+}
+
+func (r *SchemaRunner) saveCheckpoint() {
+	r.checkpoint.current = r.current
+	r.checkpoint.data = append(r.checkpoint.data[:0], r.data...)
+	r.checkpoint.counters = append(r.checkpoint.counters[:0], r.counters...)
+	r.checkpoint.lastCond = r.lastCond
+}
+
+func (r *SchemaRunner) matchCheckpoint() bool {
+	return r.checkpoint.current == r.current &&
+		r.checkpoint.lastCond == r.lastCond &&
+		bytes.Equal(r.checkpoint.counters, r.counters) &&
+		bytes.Equal(r.checkpoint.data, r.data)
+}
+
+// Reset prepares the runner to execute s with the given input.
+// Use RunStep (or RunStepChecked) to execute the program after that.
+func (r *SchemaRunner) Reset(s *ComponentSchema, input []byte) {
+	r.schema = s
+	r.current = s.Entry
+	r.lastCond = false
+
+	r.counters = r.counters[:0]
+	for range r.schema.Elems {
+		r.counters = append(r.counters, 0)
+	}
+	for _, e := range r.schema.Elems {
+		countdownData, ok := e.ExtraData.(*CountdownElemExtra)
+		if ok {
+			r.counters[e.ElemID] = uint8(countdownData.InitialValue)
+		}
+	}
+
+	r.input = input
+	r.data = r.data[:0]
+	r.data = append(r.data, input...)
+
+	r.steps = 0
+	r.power = 1
+	r.cycleLen = 0
+	r.saveCheckpoint()
+}
+
+// Data returns the current value of the program data buffer.
+// After the program is completed, it contains the output.
+//
+// The returned slice is only valid until the next Reset call.
+func (r *SchemaRunner) Data() []byte {
+	return r.data
+}
+
+// Current returns the element that will be executed by the next RunStep call.
+func (r *SchemaRunner) Current() *SchemaElem {
+	return r.current
+}
+
+// RunStep executes the current element and moves to the next one.
+// It returns the position of the next element and a flag
+// that reports whether there are more elements to execute.
+func (r *SchemaRunner) RunStep() (gmath.Vec, bool) {
+	if r.current.TileClass == "elem_output" {
+		if r.Trace != nil {
+			r.Trace(r.current, -1)
+		}
+		return gmath.Vec{}, false
+	}
+
+	prev := r.current
+
+	var dst gmath.Vec
+	switch r.current.Kind {
+	case TransformElem:
+		r.runTransformElem()
+		r.current = r.current.Next[0]
+		dst = r.current.Pos
+	case MuxElem:
+		r.current = r.current.Next[0]
+		dst = r.current.Pos
+	case SimplePipeElem, PipeConnect2Elem, InputElem:
+		r.current = r.current.Next[0]
+		dst = r.current.Pos
+	case IfElem:
+		switch r.current.TileClass {
+		case "elem_ifnot":
+			r.current = r.runIfNot()
+			dst = r.current.Pos
+		case "elem_if":
+			r.current = r.runIf()
+			dst = r.current.Pos
+		case "elem_repeater":
+			r.current = r.runRepeater()
+			dst = r.current.Pos
+		case "elem_inv_repeater":
+			r.current = r.runInvRepeater()
+			dst = r.current.Pos
+		case "elem_countdown0", "elem_countdown1", "elem_countdown2", "elem_countdown3":
+			value := r.counters[r.current.ElemID]
+			if value != 0 {
+				r.counters[r.current.ElemID]--
+				r.current = r.current.Next[0]
+			} else {
+				r.current = r.current.Next[1]
+			}
+			dst = r.current.Pos
+		default:
+			panic(fmt.Sprintf("unexpected if: %q", r.current.TileClass))
+		}
+	default:
+		panic(fmt.Sprintf("unhandled %q", r.current.TileClass))
+	}
+
+	if r.Trace != nil {
+		branch := -1
+		if prev.Kind == IfElem {
+			branch = 0
+			if r.current == prev.Next[1] {
+				branch = 1
+			}
+		}
+		r.Trace(prev, branch)
+	}
+
+	return dst, true
+}
+
+// runTransformElem applies the current transform element to the data.
+//
+// Transforms never change the data length and they are defined for any length:
+// every transform is a no-op for an empty data. If a scoped transform
+// has no letters inside its scope (like "butfirst" for a single letter),
+// the data is left unchanged too.
+func (r *SchemaRunner) runTransformElem() {
+	r.current.ExtraData.(*TransformElemExtra).apply(r.data)
+}
+
+func (r *SchemaRunner) evalIfCond() bool {
+	extra := r.current.ExtraData.(*IfElemExtra)
+	info := condKindByName[extra.CondKind]
+	if info == nil {
+		panic(fmt.Sprintf("unexpected %q elem_if cond kind", extra.CondKind))
+	}
+	return info.eval(r, extra)
+}
+
+func (r *SchemaRunner) runRepeater() *SchemaElem {
+	if r.lastCond {
+		return r.current.Next[0]
+	}
+	return r.current.Next[1]
+}
+
+func (r *SchemaRunner) runInvRepeater() *SchemaElem {
+	if !r.lastCond {
+		return r.current.Next[0]
+	}
+	return r.current.Next[1]
+}
+
+func (r *SchemaRunner) runIfNot() *SchemaElem {
+	r.lastCond = !r.evalIfCond()
+	if r.lastCond {
+		return r.current.Next[0]
+	}
+	return r.current.Next[1]
+}
+
+func (r *SchemaRunner) runIf() *SchemaElem {
+	r.lastCond = r.evalIfCond()
+	if r.lastCond {
+		return r.current.Next[0]
+	}
+	return r.current.Next[1]
+}
@@ -0,0 +1,370 @@
+package leveldata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+func loadTestTileset(t testing.TB) *Tileset {
+	t.Helper()
+	data, err := os.ReadFile("../_assets/schemas.tsj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tileset, err := LoadTileset(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tileset
+}
+
+// newLinearTemplate creates an IN -> classes... -> OUT schema template.
+// All elements are placed on the first row and connected by pipes.
+func newLinearTemplate(tileset *Tileset, classes ...string) *SchemaTemplate {
+	tmpl := &SchemaTemplate{Tileset: tileset}
+	col := 0
+	addElem := func(class string) {
+		tmpl.Elems = append(tmpl.Elems, SchemaTemplateElem{
+			Class:   class,
+			ClassID: -1,
+			Pos: gmath.Vec{
+				X: float64(col)*tileset.TileWidth + tileset.TileWidth/2,
+				Y: tileset.TileHeight / 2,
+			},
+		})
+		col++
+	}
+	addElem("elem_input")
+	for _, class := range classes {
+		addElem("pipe")
+		addElem(class)
+	}
+	addElem("pipe")
+	addElem("elem_output")
+	return tmpl
+}
+
+func newLinearSchema(t testing.TB, tileset *Tileset, classes ...string) *ComponentSchema {
+	t.Helper()
+	schema, err := NewSchemaBuilder(gmath.Vec{}, newLinearTemplate(tileset, classes...)).Build()
+	if err != nil {
+		t.Fatalf("build %v schema: %v", classes, err)
+	}
+	return schema
+}
+
+func loadTestTransformClasses(t testing.TB) []string {
+	t.Helper()
+	var classes []string
+	for _, info := range loadTestTileset(t).Elems() {
+		if info.Kind == TransformElem && !info.IsConfigurable() {
+			classes = append(classes, info.Class)
+		}
+	}
+	return classes
+}
+
+func TestTransformEdgeLengths(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	type testCase struct {
+		empty  string
+		a      string
+		z      string
+		twoLen string // for "az" input
+		full   string // for "abcdefghij" input
+	}
+	tests := map[string]testCase{
+		"apply_reverse":               {"", "a", "z", "za", "jihgfedcba"},
+		"apply_swap_halves":           {"", "a", "z", "za", "fghijabcde"},
+		"apply_rotate_left":           {"", "a", "z", "za", "bcdefghija"},
+		"apply_rotate_left_butfirst":  {"", "a", "z", "az", "acdefghijb"},
+		"apply_rotate_right":          {"", "a", "z", "za", "jabcdefghi"},
+		"apply_rotate_right_butfirst": {"", "a", "z", "az", "ajbcdefghi"},
+		"apply_add":                   {"", "b", "a", "ba", "bcdefghijk"},
+		"apply_add_butfirst":          {"", "a", "z", "aa", "acdefghijk"},
+		"apply_add_first":             {"", "b", "a", "bz", "bbcdefghij"},
+		"apply_add_last":              {"", "b", "a", "aa", "abcdefghik"},
+		"apply_add_nowrap":            {"", "b", "z", "bz", "bcdefghijk"},
+		"apply_add_butfirst_nowrap":   {"", "a", "z", "az", "acdefghijk"},
+		"apply_add_dotted":            {"", "b", "a", "ba", "bbddffhhjj"},
+		"apply_add_butfirst_dotted":   {"", "a", "z", "aa", "abddffhhjj"},
+		"apply_add_even":              {"", "a", "z", "aa", "acceeggiik"},
+		"apply_add_odd":               {"", "b", "a", "bz", "bbddffhhjj"},
+		"apply_sub":                   {"", "z", "y", "zy", "zabcdefghi"},
+		"apply_sub_butlast":           {"", "a", "z", "zz", "zabcdefghj"},
+		"apply_sub_first":             {"", "z", "y", "zz", "zbcdefghij"},
+		"apply_sub_last":              {"", "z", "y", "ay", "abcdefghii"},
+		"apply_sub_nowrap":            {"", "a", "y", "ay", "aabcdefghi"},
+		"apply_sub_undotted":          {"", "a", "z", "az", "aacceeggii"},
+		"apply_sub_even":              {"", "a", "z", "ay", "aacceeggii"},
+		"apply_sub_odd":               {"", "z", "y", "zz", "zbbddffhhj"},
+		"apply_atbash":                {"", "z", "a", "za", "zyxwvutsrq"},
+		"apply_atbash_butlast":        {"", "a", "z", "zz", "zyxwvutsrj"},
+		"apply_atbash_first":          {"", "z", "a", "zz", "zbcdefghij"},
+		"apply_polygraphic_atbash":    {"", "a", "z", "by", "abcdefghij"},
+		"apply_rot13":                 {"", "n", "m", "nm", "nopqrstuvw"},
+		"apply_rot13_butfirst":        {"", "a", "z", "am", "aopqrstuvw"},
+		"apply_rot13_butlast":         {"", "a", "z", "nz", "nopqrstuvj"},
+		"apply_rot13_first":           {"", "n", "m", "nz", "nbcdefghij"},
+		"apply_hardshift_left":        {"", "a", "a", "aa", "abcdefghij"},
+		"apply_hardshift_right":       {"", "z", "z", "zz", "zyxwvutsrq"},
+		"apply_zigzag":                {"", "a", "z", "za", "badcfehgji"},
+	}
+
+	runner := NewSchemaRunner()
+	exec := func(t *testing.T, schema *ComponentSchema, input string) string {
+		t.Helper()
+		output, err := runner.Exec(schema, input)
+		if err != nil {
+			t.Fatalf("exec(%q): %v", input, err)
+		}
+		return output
+	}
+
+	for _, class := range loadTestTransformClasses(t) {
+		test, ok := tests[class]
+		if !ok {
+			t.Errorf("%s: missing test case", class)
+			continue
+		}
+		t.Run(class, func(t *testing.T) {
+			schema := newLinearSchema(t, tileset, class)
+
+			have := testCase{
+				empty:  exec(t, schema, ""),
+				a:      exec(t, schema, "a"),
+				z:      exec(t, schema, "z"),
+				twoLen: exec(t, schema, "az"),
+				full:   exec(t, schema, "abcdefghij"),
+			}
+			if have != test {
+				t.Errorf("results mismatch:\nhave: %+v\nwant: %+v", have, test)
+			}
+
+			// Every transform should accept any input length
+			// and produce an output of the same length.
+			const alphabet = "abcdefghijklmnopqrstuvwxyz"
+			for n := 0; n <= MaxInputLen; n++ {
+				for _, input := range []string{alphabet[:n], alphabet[len(alphabet)-n:], strings.Repeat("m", n)} {
+					output := exec(t, schema, input)
+					if len(output) != len(input) {
+						t.Fatalf("exec(%q): output %q length mismatch", input, output)
+					}
+					for _, ch := range []byte(output) {
+						if ch < 'a' || ch > 'z' {
+							t.Fatalf("exec(%q): output %q contains non-letters", input, output)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCondEdgeLengths(t *testing.T) {
+	runner := NewSchemaRunner()
+	for _, info := range condKindList {
+		extra := &IfElemExtra{
+			CondKind: info.name,
+			IntArg:   info.minIntArg,
+		}
+		if info.stringArg != condArgNone {
+			extra.StringArg = "a"
+		}
+		if err := validateIfElemExtra(extra); err != nil {
+			t.Fatalf("%s: %v", info.name, err)
+		}
+		for _, input := range []string{"", "a", "z"} {
+			runner.input = []byte(input)
+			runner.data = append(runner.data[:0], input...)
+			info.eval(runner, extra) // Should not panic
+		}
+	}
+}
+
+func TestTransformInverses(t *testing.T) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz"
+	inputs := []string{"", "a", "z", "az", "acegik", "zyxwvutsrq", "hello"}
+	for n := 1; n <= MaxInputLen; n++ {
+		inputs = append(inputs, alphabet[:n], alphabet[len(alphabet)-n:])
+	}
+
+	for _, info := range loadTestTileset(t).Elems() {
+		if info.Transform == nil || !info.Transform.IsInvertible() {
+			continue
+		}
+		for _, input := range inputs {
+			data := []byte(input)
+			info.Transform.apply(data)
+			info.Transform.applyInverse(data)
+			if string(data) != input {
+				t.Errorf("%s: %q is decoded as %q", info.Class, input, data)
+			}
+		}
+	}
+}
+
+func TestConfigurableTransforms(t *testing.T) {
+	tests := []struct {
+		op        string
+		scope     string
+		rangeFrom int
+		rangeTo   int
+		input     string
+		want      string
+	}{
+		{"rot13", "odd", 0, 0, "abcde", "nbpdr"},
+		{"atbash", "dotted", 0, 0, "abcde", "zbxdv"},
+		{"atbash", "butfirst_undotted", 0, 0, "abcde", "aycwe"},
+		{"rotate_left", "butlast", 0, 0, "abcde", "bcdae"},
+		{"reverse", "even", 0, 0, "abcdef", "afcdeb"},
+		{"reverse", "dotted", 0, 0, "abcde", "ebcda"},
+		{"add", "range", 2, 3, "aaaa", "abba"},
+		{"add", "range", 2, 8, "aaaa", "abbb"},
+		{"add", "range", 5, 8, "aaaa", "aaaa"},
+		{"sub", "range_even", 1, 4, "bbbbbb", "bababb"},
+		{"zigzag", "range", 1, 3, "abcd", "bacd"},
+	}
+
+	for _, test := range tests {
+		extra, err := NewTransformElemExtra(test.op, test.scope, test.rangeFrom, test.rangeTo)
+		if err != nil {
+			t.Fatalf("%s %s: %v", test.op, test.scope, err)
+		}
+		data := []byte(test.input)
+		extra.apply(data)
+		if string(data) != test.want {
+			t.Errorf("%s %s [%d, %d] (%q): have %q, want %q",
+				test.op, test.scope, test.rangeFrom, test.rangeTo, test.input, data, test.want)
+		}
+		if extra.IsInvertible() {
+			extra.applyInverse(data)
+			if string(data) != test.input {
+				t.Errorf("%s %s [%d, %d] (%q): decoded as %q",
+					test.op, test.scope, test.rangeFrom, test.rangeTo, test.input, data)
+			}
+		}
+	}
+
+	invalid := []struct {
+		op        string
+		scope     string
+		rangeFrom int
+		rangeTo   int
+	}{
+		{"", "", 0, 0},
+		{"add2", "", 0, 0},
+		{"add", "middle", 0, 0},
+		{"add", "first_last", 0, 0},
+		{"add", "odd_even", 0, 0},
+		{"add", "range", 0, 2},
+		{"add", "range", 3, 2},
+		{"add", "range", 1, MaxInputLen + 1},
+		{"add", "first", 1, 2},
+	}
+	for _, test := range invalid {
+		_, err := NewTransformElemExtra(test.op, test.scope, test.rangeFrom, test.rangeTo)
+		if err == nil {
+			t.Errorf("%q %q [%d, %d]: expected an error", test.op, test.scope, test.rangeFrom, test.rangeTo)
+		}
+	}
+}
+
+func TestRunnerTrace(t *testing.T) {
+	schema := newLinearSchema(t, loadTestTileset(t), "apply_add", "apply_reverse")
+
+	var path []string
+	runner := NewSchemaRunner()
+	runner.Trace = func(e *SchemaElem, branch int) {
+		if branch != -1 {
+			t.Fatalf("%s: unexpected branch %d", e.TileClass, branch)
+		}
+		path = append(path, e.TileClass)
+	}
+	if _, err := runner.Exec(schema, "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "elem_input pipe apply_add pipe apply_reverse pipe elem_output"
+	if have := strings.Join(path, " "); have != want {
+		t.Fatalf("unexpected path:\nhave: %s\nwant: %s", have, want)
+	}
+}
+
+func TestRunnerNonTerminating(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	// The special branch leads back to the condition element,
+	// the data is not changed inside the loop.
+	const levelTemplate = `
+grid:
+.  .  OUT   .
+.  .  P@270 .
+IN P  %s    A@90~
+.  .  SA@270~ A@180~
+settings:
+num_keywords: 1
+keywords: %s
+props:
+2,2: cond_kind=len_even
+`
+	tests := []struct {
+		elem     string
+		loops    string
+		finishes string
+	}{
+		{"IF", "ab", "abc"},
+		{"IFN", "abc", "ab"},
+	}
+	for _, test := range tests {
+		level := fmt.Sprintf(levelTemplate, test.elem, test.finishes)
+		tmpl, err := LoadTextTemplate(tileset, []byte(level))
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema := buildTestSchema(t, tmpl)
+
+		runner := NewSchemaRunner()
+		if _, err := runner.Exec(schema, test.finishes); err != nil {
+			t.Fatalf("%s: %q: %v", test.elem, test.finishes, err)
+		}
+
+		_, err = runner.Exec(schema, test.loops)
+		if !errors.Is(err, ErrNonTerminating) || !strings.Contains(err.Error(), "a loop detected") {
+			t.Fatalf("%s: %q: unexpected error: %v", test.elem, test.loops, err)
+		}
+
+		runner.MaxSteps = 3
+		_, err = runner.Exec(schema, test.loops)
+		if !errors.Is(err, ErrNonTerminating) || !strings.Contains(err.Error(), "step limit (3) exceeded") {
+			t.Fatalf("%s: %q: unexpected error: %v", test.elem, test.loops, err)
+		}
+		// The step limit applies to the terminating inputs too.
+		_, err = runner.Exec(schema, test.finishes)
+		if !errors.Is(err, ErrNonTerminating) {
+			t.Fatalf("%s: %q: unexpected error: %v", test.elem, test.finishes, err)
+		}
+
+		// The step-by-step execution has the same guards.
+		runner.MaxSteps = 0
+		runner.Reset(schema, []byte(test.loops))
+		for {
+			_, hasMore, err := runner.RunStepChecked()
+			if err != nil {
+				if !errors.Is(err, ErrNonTerminating) {
+					t.Fatalf("%s: %q: unexpected error: %v", test.elem, test.loops, err)
+				}
+				break
+			}
+			if !hasMore {
+				t.Fatalf("%s: %q: the program is completed", test.elem, test.loops)
+			}
+		}
+	}
+}
@@ -2,16 +2,22 @@ package leveldata
 
 import (
 	"math"
-	"strings"
 
 	"github.com/quasilyte/gmath"
 )
 
+// MaxInputLen is the max number of letters a component input can have.
+const MaxInputLen = 10
+
 type ComponentSchema struct {
 	Entry *SchemaElem
 
 	Elems []*SchemaElem
 
+	// NumCols and NumRows describe the schema grid size.
+	NumCols int
+	NumRows int
+
 	NumKeywords     int
 	Keywords        []string
 	EncodedKeywords []string
@@ -25,6 +31,25 @@ type ComponentSchema struct {
 	HasNegation      bool
 }
 
+func (s *ComponentSchema) addFeature(tag string) {
+	switch tag {
+	case "cond_transform":
+		s.HasCondTransform = true
+	case "polygraphic":
+		s.HasPolygraphic = true
+	case "atbash":
+		s.HasAtbash = true
+	case "rot13":
+		s.HasRot13 = true
+	case "incdec":
+		s.HasIncDec = true
+	case "shift":
+		s.HasShift = true
+	case "negation":
+		s.HasNegation = true
+	}
+}
+
 type SchemaElemKind int
 
 const (
@@ -48,6 +73,10 @@ type SchemaElem struct {
 
 	Pos gmath.Vec
 
+	// Row and Col are the element grid coordinates.
+	Row int
+	Col int
+
 	Rotation gmath.Rad
 
 	ExtraData any
@@ -122,29 +151,13 @@ func getElemShape(class string, pos gmath.Vec, rotation gmath.Rad, extraData any
 	return shape
 }
 
-func getSchemaElemKind(tileClass string) SchemaElemKind {
-	switch tileClass {
-	case "elem_input":
-		return InputElem
-	case "elem_output":
-		return OutputElem
-	case "elem_mux":
-		return MuxElem
-	case "elem_if", "elem_ifnot", "elem_repeater", "elem_inv_repeater":
-		return IfElem
-	case "pipe_connect2":
-		return PipeConnect2Elem
-	}
-
-	if strings.HasPrefix(tileClass, "apply_") {
-		return TransformElem
-	}
-	if strings.Contains(tileClass, "_countdown") {
-		return IfElem
-	}
-	if strings.Contains(tileClass, "pipe") {
-		return SimplePipeElem
-	}
-
-	return UnknownElem
+// elemKindByName maps the tileset "kind" property values to the element kinds.
+var elemKindByName = map[string]SchemaElemKind{
+	"input":         InputElem,
+	"output":        OutputElem,
+	"mux":           MuxElem,
+	"pipe":          SimplePipeElem,
+	"pipe_connect2": PipeConnect2Elem,
+	"if":            IfElem,
+	"transform":     TransformElem,
 }
@@ -0,0 +1,148 @@
+package leveldata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// DefaultSolverMaxCandidates is used when SolverConfig.MaxCandidates is 0.
+// It's enough to check every input of up to 6 letters, which takes seconds.
+// Longer inputs require an explicitly raised limit.
+const DefaultSolverMaxCandidates int64 = 26 * 26 * 26 * 26 * 26 * 26
+
+// ErrSearchSpaceTooBig is reported when the solver would need to
+// check more inputs than it's allowed to.
+var ErrSearchSpaceTooBig = errors.New("search space is too big")
+
+type SolverConfig struct {
+	// MaxCandidates limits the number of inputs the solver can try.
+	// Zero value means DefaultSolverMaxCandidates.
+	//
+	// A brute-force search for an n-letter output checks 26^n inputs.
+	// Every extra letter makes the search 26 times slower:
+	// 6 letters take seconds, while 8 letters can take hours.
+	// Checking every MaxInputLen-letter input requires a 26^10 limit.
+	MaxCandidates int64
+
+	// NumWorkers is the number of goroutines used for the search.
+	// Zero value means runtime.NumCPU().
+	NumWorkers int
+}
+
+// Solve returns all inputs that are encoded as the given output.
+// The result is sorted.
+//
+// If the schema can be decoded analytically (see Decode), the result is computed instantly.
+// Otherwise it's a brute-force search over the a-z inputs.
+// Since transforms never change the data length,
+// only the inputs of the output length are checked.
+// No other pruning is done: a transform can move letters around
+// (like reverse does), so an input prefix doesn't determine the output prefix.
+// The analytical decoding is not affected by the MaxCandidates limit.
+func Solve(schema *ComponentSchema, encoded string, config SolverConfig) ([]string, error) {
+	if config.MaxCandidates == 0 {
+		config.MaxCandidates = DefaultSolverMaxCandidates
+	}
+	if config.NumWorkers == 0 {
+		config.NumWorkers = runtime.NumCPU()
+	}
+
+	inputLen := len(encoded)
+	if inputLen > MaxInputLen {
+		// This output can't be produced by a valid input.
+		return nil, nil
+	}
+
+	// Branch-free programs can be decoded instantly.
+	// Decode is precise: the result is the only possible decoding.
+	if decoded, err := Decode(schema, encoded); err == nil {
+		runner := NewSchemaRunner()
+		if err := runner.exec(schema, []byte(decoded)); err == nil && bytes.Equal(runner.data, []byte(encoded)) {
+			return []string{decoded}, nil
+		}
+		// The encoded string contains something that no input can produce.
+		return nil, nil
+	}
+
+	numCandidates := int64(1)
+	for i := 0; i < inputLen; i++ {
+		numCandidates *= 26
+	}
+	if numCandidates > config.MaxCandidates {
+		return nil, fmt.Errorf("%w: %d-letter output requires %d checks (the limit is %d)",
+			ErrSearchSpaceTooBig, inputLen, numCandidates, config.MaxCandidates)
+	}
+
+	target := []byte(encoded)
+
+	if inputLen == 0 {
+		runner := NewSchemaRunner()
+		if err := runner.exec(schema, nil); err == nil && len(runner.data) == 0 {
+			return []string{""}, nil
+		}
+		return nil, nil
+	}
+
+	// Every job checks all inputs that start with a given letter.
+	jobs := make(chan byte, 26)
+	for ch := byte('a'); ch <= 'z'; ch++ {
+		jobs <- ch
+	}
+	close(jobs)
+
+	var result []string
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(config.NumWorkers)
+	for i := 0; i < config.NumWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			runner := NewSchemaRunner()
+			input := make([]byte, inputLen)
+			for firstLetter := range jobs {
+				found := solveWithPrefix(runner, schema, target, input, firstLetter)
+				if len(found) != 0 {
+					mu.Lock()
+					result = append(result, found...)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Strings(result)
+	return result, nil
+}
+
+func solveWithPrefix(runner *SchemaRunner, schema *ComponentSchema, target, input []byte, firstLetter byte) []string {
+	var result []string
+
+	input[0] = firstLetter
+	for i := 1; i < len(input); i++ {
+		input[i] = 'a'
+	}
+	for {
+		// Inputs that never reach the output can't be decodings.
+		if err := runner.exec(schema, input); err == nil && bytes.Equal(runner.data, target) {
+			result = append(result, string(input))
+		}
+
+		// Move to the next input; the first letter is fixed.
+		i := len(input) - 1
+		for i >= 1 && input[i] == 'z' {
+			input[i] = 'a'
+			i--
+		}
+		if i < 1 {
+			break
+		}
+		input[i]++
+	}
+
+	return result
+}
@@ -0,0 +1,104 @@
+package leveldata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testLossyLevel applies a non-wrapping +1 to the first letter:
+// both "y" and "z" become "z".
+const testLossyLevel = `
+grid:
+IN P X P OUT
+settings:
+num_keywords: 1
+keywords: cab
+props:
+2,0: op=add_nowrap scope=first
+`
+
+// testBranchingLevel sends the inputs with "a" letter through the bottom branch.
+const testBranchingLevel = `
+grid:
+.  .  A@270   P SUB/F P A
+IN P  IF      . .     . OUT
+.  .  SA@270~ P ADD/L P A@180~
+settings:
+num_keywords: 1
+keywords: abc
+props:
+2,1: cond_kind=contains_letter string_arg=a
+`
+
+func newTextSchema(t testing.TB, tileset *Tileset, level string) *ComponentSchema {
+	t.Helper()
+	tmpl, err := LoadTextTemplate(tileset, []byte(level))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buildTestSchema(t, tmpl)
+}
+
+func TestSolveLinear(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	schema := newLinearSchema(t, tileset, "apply_add", "apply_reverse", "apply_rot13")
+	runner := NewSchemaRunner()
+	for _, input := range []string{"a", "hello", "abcdefghij"} {
+		encoded, err := runner.Exec(schema, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The analytical decoding doesn't need any candidates checks.
+		decoded, err := Solve(schema, encoded, SolverConfig{MaxCandidates: 1})
+		if err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		if !reflect.DeepEqual(decoded, []string{input}) {
+			t.Fatalf("%q: unexpected result %v", input, decoded)
+		}
+	}
+}
+
+func TestSolveBruteForce(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	lossy := newTextSchema(t, tileset, testLossyLevel)
+	branching := newTextSchema(t, tileset, testBranchingLevel)
+
+	tests := []struct {
+		schema  *ComponentSchema
+		encoded string
+		want    []string
+	}{
+		{lossy, "zab", []string{"yab", "zab"}},
+		{lossy, "dab", []string{"cab"}},
+		{lossy, "aab", nil},
+		{branching, "ac", []string{"ab", "bc"}},
+		{branching, "ab", []string{"aa", "bb"}},
+		{branching, "zz", nil}, // "zy" and "az" take the other branches
+	}
+	for _, test := range tests {
+		if _, err := Decode(test.schema, test.encoded); err == nil {
+			t.Fatalf("%q: expected the brute-force search", test.encoded)
+		}
+		decoded, err := Solve(test.schema, test.encoded, SolverConfig{})
+		if err != nil {
+			t.Fatalf("%q: %v", test.encoded, err)
+		}
+		if !reflect.DeepEqual(decoded, test.want) {
+			t.Fatalf("%q: unexpected result:\nhave: %v\nwant: %v", test.encoded, decoded, test.want)
+		}
+	}
+
+	_, err := Solve(lossy, "zab", SolverConfig{MaxCandidates: 26 * 26})
+	if !errors.Is(err, ErrSearchSpaceTooBig) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The default limit stops the long searches.
+	_, err = Solve(branching, "abcdefg", SolverConfig{})
+	if !errors.Is(err, ErrSearchSpaceTooBig) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
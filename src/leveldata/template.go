@@ -1,7 +1,9 @@
 package leveldata
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/quasilyte/ge/tiled"
@@ -9,8 +11,14 @@ import (
 )
 
 type SchemaTemplate struct {
-	Tileset     *tiled.Tileset
-	Elems       []SchemaTemplateElem
+	Tileset *Tileset
+	Elems   []SchemaTemplateElem
+
+	// NumCols and NumRows describe the schema grid size.
+	// Zero values mean DefaultNumSchemaCols and DefaultNumSchemaRows.
+	NumCols int
+	NumRows int
+
 	NumKeywords int
 	Keywords    []string
 	Hints       []SchemaHintTemplate
@@ -43,16 +51,35 @@ type IfElemExtra struct {
 	IntArg    int
 }
 
-func ValidateLevelData(tileset *tiled.Tileset, levelData []byte) error {
+func ValidateLevelData(tileset *Tileset, levelData []byte) error {
 	tmpl, err := LoadLevelTemplate(tileset, levelData)
 	if err != nil {
 		return err
 	}
-	_, err = NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
-	return err
+	schema, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		return err
+	}
+	runner := NewSchemaRunner()
+	for _, k := range schema.Keywords {
+		if _, err := runner.Exec(schema, k); err != nil {
+			return fmt.Errorf("keyword %q: %w", k, err)
+		}
+	}
+	return nil
 }
 
-func LoadLevelTemplate(tileset *tiled.Tileset, levelData []byte) (*SchemaTemplate, error) {
+// LoadLevelTemplate decodes a schema template from either
+// the native level format or a Tiled map.
+// The older levels are migrated to the LevelFormatVersion first.
+func LoadLevelTemplate(tileset *Tileset, levelData []byte) (*SchemaTemplate, error) {
+	levelData, _, err := MigrateLevelData(tileset, levelData)
+	if err != nil {
+		return nil, err
+	}
+	if IsNativeLevel(levelData) {
+		return LoadNativeTemplate(tileset, levelData)
+	}
 	m, err := tiled.UnmarshalMap(levelData)
 	if err != nil {
 		return nil, err
@@ -60,7 +87,7 @@ func LoadLevelTemplate(tileset *tiled.Tileset, levelData []byte) (*SchemaTemplat
 	return TilemapToTemplate(tileset, m)
 }
 
-func TilemapToTemplate(tileset *tiled.Tileset, m *tiled.Map) (*SchemaTemplate, error) {
+func TilemapToTemplate(tileset *Tileset, m *tiled.Map) (*SchemaTemplate, error) {
 	calcObjectPos := func(o tiled.Object) gmath.Vec {
 		pos := gmath.Vec{X: float64(o.X) + tileset.TileWidth/2, Y: float64(o.Y) - tileset.TileHeight/2}
 		switch o.Rotation {
@@ -78,6 +105,8 @@ func TilemapToTemplate(tileset *tiled.Tileset, m *tiled.Map) (*SchemaTemplate, e
 	var result SchemaTemplate
 
 	result.Tileset = tileset
+	result.NumCols = m.Width
+	result.NumRows = m.Height
 
 	elemList := make([]SchemaTemplateElem, 0, 24)
 
@@ -89,6 +118,9 @@ func TilemapToTemplate(tileset *tiled.Tileset, m *tiled.Map) (*SchemaTemplate, e
 		id := o.GID - ref.FirstGID
 		t := tileset.TileByID(id)
 		pos := calcObjectPos(o)
+		if t == nil {
+			return nil, fmt.Errorf("%v: unknown tile gid %d", pos, o.GID)
+		}
 		if t.Class == "settings" {
 			if foundSettings {
 				return nil, fmt.Errorf("%v: found more than one settings element", pos)
@@ -113,41 +145,18 @@ func TilemapToTemplate(tileset *tiled.Tileset, m *tiled.Map) (*SchemaTemplate, e
 		}
 		elem := SchemaTemplateElem{
 			Pos:      pos,
-			ClassID:  t.Index,
+			ClassID:  id,
 			Class:    t.Class,
 			Rotation: gmath.DegToRad(float64(o.Rotation)),
 		}
 		switch elem.Class {
 		case "angle_pipe", "special_angle_pipe":
-			if o.FlippedVertically() {
-				return nil, fmt.Errorf("%v: vertical flipping is obsolete", pos)
-			}
 			elem.ExtraData = &AngleElemExtra{
 				FlipHorizontally: o.FlippedHorizontally(),
 			}
-		case "elem_countdown0", "elem_countdown1", "elem_countdown2", "elem_countdown3":
-			extra := &CountdownElemExtra{
-				InitialValue: 3,
-			}
-			switch elem.Class {
-			case "elem_countdown0":
-				extra.InitialValue = 0
-			case "elem_countdown1":
-				extra.InitialValue = 1
-			case "elem_countdown2":
-				extra.InitialValue = 2
-			}
-			elem.ExtraData = extra
-		case "elem_if", "elem_ifnot":
-			extra := &IfElemExtra{
-				CondKind:  o.GetStringProp("cond_kind", ""),
-				StringArg: o.GetStringProp("string_arg", ""),
-				IntArg:    o.GetIntProp("int_arg", 0),
-			}
-			if extra.CondKind == "" {
-				return nil, fmt.Errorf("%v: elem_if cond property is empty", pos)
-			}
-			elem.ExtraData = extra
+		}
+		if err := initElemExtra(tileset, &elem, &o); err != nil {
+			return nil, fmt.Errorf("%v: %s: %w", pos, elem.Class, err)
 		}
 		elemList = append(elemList, elem)
 	}
@@ -156,3 +165,80 @@ func TilemapToTemplate(tileset *tiled.Tileset, m *tiled.Map) (*SchemaTemplate, e
 
 	return &result, nil
 }
+
+// elemProps is an element properties source, like a Tiled object.
+type elemProps interface {
+	GetStringProp(name, defaultValue string) string
+	GetIntProp(name string, defaultValue int) int
+}
+
+// mapProps is an elemProps implementation for the formats that
+// store props as a simple key-value map.
+type mapProps map[string]string
+
+func (p mapProps) GetStringProp(name, defaultValue string) string {
+	if v, ok := p[name]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+func (p mapProps) GetIntProp(name string, defaultValue int) int {
+	if v, ok := p[name]; ok {
+		// The values are validated by the format parsers.
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+	return defaultValue
+}
+
+// initElemExtra sets the element ExtraData that depends on its class and properties.
+func initElemExtra(tileset *Tileset, elem *SchemaTemplateElem, props elemProps) error {
+	switch elem.Class {
+	case "elem_countdown0", "elem_countdown1", "elem_countdown2", "elem_countdown3":
+		extra := &CountdownElemExtra{
+			InitialValue: 3,
+		}
+		switch elem.Class {
+		case "elem_countdown0":
+			extra.InitialValue = 0
+		case "elem_countdown1":
+			extra.InitialValue = 1
+		case "elem_countdown2":
+			extra.InitialValue = 2
+		}
+		elem.ExtraData = extra
+	case "elem_if", "elem_ifnot":
+		extra := &IfElemExtra{
+			CondKind:  props.GetStringProp("cond_kind", ""),
+			StringArg: props.GetStringProp("string_arg", ""),
+			IntArg:    props.GetIntProp("int_arg", 0),
+		}
+		if extra.CondKind == "" {
+			return errors.New("cond_kind property is empty")
+		}
+		elem.ExtraData = extra
+	}
+	if info := tileset.ElemByClass(elem.Class); info != nil && info.Kind == TransformElem {
+		extra, err := loadTransformElemExtra(info, props)
+		if err != nil {
+			return err
+		}
+		elem.ExtraData = extra
+	}
+	return nil
+}
+
+func loadTransformElemExtra(info *ElemInfo, props elemProps) (*TransformElemExtra, error) {
+	op := props.GetStringProp("op", "")
+	scope := props.GetStringProp("scope", "")
+	rangeFrom := props.GetIntProp("range_from", 0)
+	rangeTo := props.GetIntProp("range_to", 0)
+	if !info.IsConfigurable() {
+		if op != "" || scope != "" || rangeFrom != 0 || rangeTo != 0 {
+			return nil, errors.New("op, scope and range properties are only allowed for configurable transforms")
+		}
+		return info.Transform, nil
+	}
+	return NewTransformElemExtra(op, scope, rangeFrom, rangeTo)
+}
@@ -1,4 +1,8 @@
-package main
+package leveldata
+
+import (
+	"hash/fnv"
+)
 
 func checkAnagram(s1, s2 []byte) bool {
 	if len(s1) != len(s2) {
@@ -20,7 +24,7 @@ func checkAnagram(s1, s2 []byte) bool {
 	return true
 }
 
-func rotateCharsRight(chars []byte) {
+func RotateCharsRight(chars []byte) {
 	if len(chars) == 0 {
 		return
 	}
@@ -31,7 +35,7 @@ func rotateCharsRight(chars []byte) {
 	chars[0] = last
 }
 
-func rotateCharsLeft(chars []byte) {
+func RotateCharsLeft(chars []byte) {
 	if len(chars) == 0 {
 		return
 	}
@@ -42,47 +46,37 @@ func rotateCharsLeft(chars []byte) {
 	chars[len(chars)-1] = first
 }
 
-func mapChars(chars []byte, f func(ch byte) byte) {
-	for i, ch := range chars {
-		chars[i] = f(ch)
+func reverseChars(chars []byte) {
+	for i, j := 0, len(chars)-1; i < j; i, j = i+1, j-1 {
+		chars[i], chars[j] = chars[j], chars[i]
 	}
 }
 
-func mapEvenChars(chars []byte, f func(ch byte) byte) {
-	for i, ch := range chars {
-		pos := i + 1
-		if pos%2 == 0 {
-			chars[i] = f(ch)
-		}
-	}
-}
-
-func mapOddChars(chars []byte, f func(ch byte) byte) {
-	for i, ch := range chars {
-		pos := i + 1
-		if pos%2 != 0 {
-			chars[i] = f(ch)
-		}
-	}
-}
-
-func mapCharsButfirst(chars []byte, f func(ch byte) byte) {
+func swapHalves(chars []byte) {
 	if len(chars) < 2 {
 		return
 	}
-	data := chars[1:]
-	for i, ch := range data {
-		data[i] = f(ch)
+	mid := len(chars) / 2
+	end := mid
+	offset := 0
+	if len(chars)%2 != 0 {
+		offset = 1
+	}
+	for i := 0; i < end; i++ {
+		j := mid + i + offset
+		chars[i], chars[j] = chars[j], chars[i]
 	}
 }
 
-func mapCharsButlast(chars []byte, f func(ch byte) byte) {
-	if len(chars) < 2 {
-		return
+func zigzagChars(chars []byte) {
+	for i := 0; i < len(chars)-1; i += 2 {
+		chars[i], chars[i+1] = chars[i+1], chars[i]
 	}
-	data := chars[:len(chars)-1]
-	for i, ch := range data {
-		data[i] = f(ch)
+}
+
+func mapChars(chars []byte, f func(ch byte) byte) {
+	for i, ch := range chars {
+		chars[i] = f(ch)
 	}
 }
 
@@ -102,8 +96,8 @@ func polygraphicAtbash(chars []byte) {
 			continue
 		}
 		if expectedNext == chars[i+1] {
-			chars[i] = incChar(chars[i])
-			chars[i+1] = decChar(chars[i+1])
+			chars[i] = IncChar(chars[i])
+			chars[i+1] = DecChar(chars[i+1])
 			i++
 		}
 	}
@@ -124,44 +118,63 @@ var dottedChars = [256]bool{
 	'z': true,
 }
 
-func incCharDotted(b byte) byte {
-	if !dottedChars[b] {
+func hardshiftLeftChar(b byte) byte {
+	if b < 'n' {
 		return b
 	}
-	return incChar(b)
+	return atbashChar(b)
 }
 
-func decCharUndotted(b byte) byte {
-	if dottedChars[b] {
-		return b
+func hardshiftRightChar(b byte) byte {
+	if b < 'n' {
+		return atbashChar(b)
 	}
-	return decChar(b)
+	return b
 }
 
-func incChar(b byte) byte {
+func rot13Char(b byte) byte {
+	if b < 'n' {
+		return 'n' + (b - 'a')
+	}
+	return 'a' + (b - 'n')
+}
+
+func atbashChar(b byte) byte {
+	return 'a' + (25 - (b - 'a'))
+}
+
+func IncChar(b byte) byte {
 	if b+1 > 'z' {
 		return 'a'
 	}
 	return b + 1
 }
 
-func decChar(b byte) byte {
+func DecChar(b byte) byte {
 	if b-1 < 'a' {
 		return 'z'
 	}
 	return b - 1
 }
 
-func (r *schemaRunner) decCharNowrap(b byte) byte {
+func decCharNowrap(b byte) byte {
 	if b-1 < 'a' {
 		return 'a'
 	}
 	return b - 1
 }
 
-func (r *schemaRunner) incCharNowrap(b byte) byte {
+func incCharNowrap(b byte) byte {
 	if b+1 > 'z' {
 		return 'z'
 	}
 	return b + 1
 }
+
+// FNVHash returns the 64-bit FNV-1a hash of b.
+// It's used by the fnv_even condition and by the game to pick the masked letters.
+func FNVHash(b []byte) uint64 {
+	hash := fnv.New64a()
+	hash.Write(b)
+	return hash.Sum64()
+}
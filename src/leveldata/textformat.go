@@ -0,0 +1,428 @@
+package leveldata
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/quasilyte/gmath"
+)
+
+// The text format is a human-readable SchemaTemplate representation.
+// It's designed to be used in tests and level diffs.
+//
+//	# Comments start with '#'.
+//	grid:
+//	.  .  A@270   P SUB/F P A
+//	IN P  IF      . .     . OUT
+//	.  .  SA@270~ P ADD/L P A@180~
+//	settings:
+//	num_keywords: 2
+//	keywords: foo bar baz
+//	hints:
+//	1,2.5: first line\nsecond line
+//	props:
+//	2,1: cond_kind=len_eq int_arg=3
+//
+// The grid section describes the schema elements, one row per line.
+// Every cell is an element code (see ElemInfo.Code) or "." for an empty cell.
+// A code can be followed by a rotation marker (@90, @180 or @270, clockwise)
+// and a "~" horizontal flip marker (only for the angle pipes), like "A@90~".
+// The grid size is defined by the number of rows and columns.
+//
+// The hints and props sections are keyed by "col,row" cell coordinates.
+// Hint coordinates can be fractional; hint text uses "\n" for line breaks.
+// Props are element properties, the same as Tiled object properties:
+// cond_kind, string_arg and int_arg for the elem_if; op, scope, range_from
+// and range_to for the configurable transforms.
+
+// LoadTextTemplate decodes a schema template from its text format.
+func LoadTextTemplate(tileset *Tileset, data []byte) (*SchemaTemplate, error) {
+	result := &SchemaTemplate{Tileset: tileset}
+
+	type gridCell struct {
+		token string
+		col   int
+		row   int
+		line  int
+	}
+	var cells []gridCell
+	propsByCell := make(map[[2]int]mapProps)
+
+	section := ""
+	numCols := -1
+	sections := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch line {
+		case "grid:", "settings:", "hints:", "props:":
+			section = strings.TrimSuffix(line, ":")
+			if sections[section] {
+				return nil, fmt.Errorf("line %d: duplicated %s section", lineNum, section)
+			}
+			sections[section] = true
+			continue
+		}
+
+		switch section {
+		case "grid":
+			tokens := strings.Fields(line)
+			if numCols == -1 {
+				numCols = len(tokens)
+			}
+			if len(tokens) != numCols {
+				return nil, fmt.Errorf("line %d: expected %d columns, found %d", lineNum, numCols, len(tokens))
+			}
+			for col, tok := range tokens {
+				if tok == "." {
+					continue
+				}
+				cells = append(cells, gridCell{token: tok, col: col, row: result.NumRows, line: lineNum})
+			}
+			result.NumRows++
+
+		case "settings":
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a key: value pair", lineNum)
+			}
+			value = strings.TrimSpace(value)
+			switch strings.TrimSpace(key) {
+			case "num_keywords":
+				v, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: num_keywords: %w", lineNum, err)
+				}
+				result.NumKeywords = v
+			case "keywords":
+				result.Keywords = strings.Fields(value)
+			default:
+				return nil, fmt.Errorf("line %d: unknown setting %q", lineNum, key)
+			}
+
+		case "hints":
+			// The hint text can start or end with spaces,
+			// so only the ": " separator is trimmed.
+			rawLine := strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace)
+			key, text, ok := strings.Cut(rawLine, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a col,row: text pair", lineNum)
+			}
+			x, y, err := parseTextCoords(key)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			result.Hints = append(result.Hints, SchemaHintTemplate{
+				Text: unescapeHintText(strings.TrimPrefix(text, " ")),
+				Pos:  gmath.Vec{X: x * tileset.TileWidth, Y: y * tileset.TileHeight},
+			})
+
+		case "props":
+			key, list, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a col,row: props pair", lineNum)
+			}
+			x, y, err := parseTextCoords(key)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			cellKey := [2]int{int(x), int(y)}
+			if float64(cellKey[0]) != x || float64(cellKey[1]) != y {
+				return nil, fmt.Errorf("line %d: props cell coordinates should be integers", lineNum)
+			}
+			if _, ok := propsByCell[cellKey]; ok {
+				return nil, fmt.Errorf("line %d: duplicated props for %d,%d", lineNum, cellKey[0], cellKey[1])
+			}
+			props := make(mapProps)
+			for _, kv := range strings.Fields(list) {
+				k, v, ok := strings.Cut(kv, "=")
+				if !ok {
+					return nil, fmt.Errorf("line %d: expected a key=value prop, found %q", lineNum, kv)
+				}
+				isInt, known := textPropKinds[k]
+				if !known {
+					return nil, fmt.Errorf("line %d: unknown prop %q", lineNum, k)
+				}
+				if _, err := strconv.Atoi(v); isInt && err != nil {
+					return nil, fmt.Errorf("line %d: %s: expected an integer value, found %q", lineNum, k, v)
+				}
+				props[k] = v
+			}
+			propsByCell[cellKey] = props
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected data outside of a section", lineNum)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !sections["grid"] {
+		return nil, errors.New("grid section is missing")
+	}
+	if len(result.Keywords) == 0 {
+		return nil, errors.New("settings.keywords is empty")
+	}
+	result.NumCols = gmath.ClampMin(numCols, 0)
+
+	for _, c := range cells {
+		elem, err := parseTextElem(tileset, c.token)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %d,%d: %w", c.line, c.col, c.row, err)
+		}
+		elem.Pos = gmath.Vec{
+			X: (float64(c.col) + 0.5) * tileset.TileWidth,
+			Y: (float64(c.row) + 0.5) * tileset.TileHeight,
+		}
+		cellKey := [2]int{c.col, c.row}
+		props, ok := propsByCell[cellKey]
+		if !ok {
+			props = mapProps{}
+		}
+		delete(propsByCell, cellKey)
+		if err := initElemExtra(tileset, &elem, props); err != nil {
+			return nil, fmt.Errorf("line %d: %d,%d: %s: %w", c.line, c.col, c.row, elem.Class, err)
+		}
+		result.Elems = append(result.Elems, elem)
+	}
+	if len(propsByCell) != 0 {
+		var keys []string
+		for k := range propsByCell {
+			keys = append(keys, fmt.Sprintf("%d,%d", k[0], k[1]))
+		}
+		sort.Strings(keys)
+		return nil, fmt.Errorf("found props for the empty cells: %s", strings.Join(keys, " "))
+	}
+
+	return result, nil
+}
+
+// TemplateToText encodes the schema template in the text format.
+//
+// It's an inverse of LoadTextTemplate: loading the result
+// gives an equivalent template.
+func TemplateToText(t *SchemaTemplate) ([]byte, error) {
+	numCols := t.NumCols
+	numRows := t.NumRows
+	if numCols == 0 && numRows == 0 {
+		numCols = DefaultNumSchemaCols
+		numRows = DefaultNumSchemaRows
+	}
+	tileWidth := t.Tileset.TileWidth
+	tileHeight := t.Tileset.TileHeight
+
+	grid := make([]string, numCols*numRows)
+	type cellProps struct {
+		col   int
+		row   int
+		props string
+	}
+	var propsList []cellProps
+	for _, e := range t.Elems {
+		col, row, err := templateElemCell(t, e)
+		if err != nil {
+			return nil, err
+		}
+		if col < 0 || col >= numCols || row < 0 || row >= numRows {
+			return nil, fmt.Errorf("%v: %s: element is outside of the %dx%d grid", e.Pos, e.Class, numCols, numRows)
+		}
+		if grid[row*numCols+col] != "" {
+			return nil, fmt.Errorf("%v: %s: cell %d,%d is already occupied", e.Pos, e.Class, col, row)
+		}
+		token, props, err := formatTextElem(t.Tileset, e)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s: %w", e.Pos, e.Class, err)
+		}
+		grid[row*numCols+col] = token
+		if props != "" {
+			propsList = append(propsList, cellProps{col: col, row: row, props: props})
+		}
+	}
+
+	// All cells in a column have the same width.
+	colWidth := make([]int, numCols)
+	for i, token := range grid {
+		if token == "" {
+			grid[i] = "."
+		}
+		colWidth[i%numCols] = gmath.ClampMin(colWidth[i%numCols], len(grid[i]))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("grid:\n")
+	for row := 0; row < numRows; row++ {
+		var line strings.Builder
+		for col := 0; col < numCols; col++ {
+			token := grid[row*numCols+col]
+			line.WriteString(token)
+			if col != numCols-1 {
+				line.WriteString(strings.Repeat(" ", colWidth[col]-len(token)+1))
+			}
+		}
+		buf.WriteString(strings.TrimRight(line.String(), " "))
+		buf.WriteByte('\n')
+	}
+
+	buf.WriteString("settings:\n")
+	fmt.Fprintf(&buf, "num_keywords: %d\n", t.NumKeywords)
+	fmt.Fprintf(&buf, "keywords: %s\n", strings.Join(t.Keywords, " "))
+
+	if len(t.Hints) != 0 {
+		buf.WriteString("hints:\n")
+		for _, h := range t.Hints {
+			fmt.Fprintf(&buf, "%s,%s: %s\n",
+				strconv.FormatFloat(h.Pos.X/tileWidth, 'g', -1, 64),
+				strconv.FormatFloat(h.Pos.Y/tileHeight, 'g', -1, 64),
+				escapeHintText(h.Text))
+		}
+	}
+
+	if len(propsList) != 0 {
+		sort.Slice(propsList, func(i, j int) bool {
+			if propsList[i].row != propsList[j].row {
+				return propsList[i].row < propsList[j].row
+			}
+			return propsList[i].col < propsList[j].col
+		})
+		buf.WriteString("props:\n")
+		for _, p := range propsList {
+			fmt.Fprintf(&buf, "%d,%d: %s\n", p.col, p.row, p.props)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func parseTextElem(tileset *Tileset, token string) (SchemaTemplateElem, error) {
+	var elem SchemaTemplateElem
+
+	code := token
+	flip := strings.HasSuffix(code, "~")
+	code = strings.TrimSuffix(code, "~")
+	rotation := 0
+	if i := strings.IndexByte(code, '@'); i != -1 {
+		switch code[i+1:] {
+		case "90":
+			rotation = 90
+		case "180":
+			rotation = 180
+		case "270":
+			rotation = 270
+		default:
+			return elem, fmt.Errorf("%q: invalid rotation, expected @90, @180 or @270", token)
+		}
+		code = code[:i]
+	}
+
+	info := tileset.ElemByCode(code)
+	if info == nil {
+		return elem, fmt.Errorf("%q: unknown element code", token)
+	}
+	elem.Class = info.Class
+	elem.ClassID = info.TileID
+	elem.Rotation = gmath.DegToRad(float64(rotation))
+	switch info.Class {
+	case "angle_pipe", "special_angle_pipe":
+		elem.ExtraData = &AngleElemExtra{FlipHorizontally: flip}
+	default:
+		if flip {
+			return elem, fmt.Errorf("%q: only angle pipes can be flipped", token)
+		}
+	}
+
+	return elem, nil
+}
+
+func formatTextElem(tileset *Tileset, e SchemaTemplateElem) (token, props string, err error) {
+	info := tileset.ElemByClass(e.Class)
+	if info == nil {
+		return "", "", errors.New("unknown element class")
+	}
+
+	token = info.Code
+	degrees := rotationDegrees(e.Rotation)
+	switch degrees {
+	case 0:
+	case 90:
+		token += "@90"
+	case 180:
+		token += "@180"
+	case 270:
+		token += "@270"
+	default:
+		return "", "", fmt.Errorf("unsupported rotation angle %d", degrees)
+	}
+	if extra, ok := e.ExtraData.(*AngleElemExtra); ok && extra.FlipHorizontally {
+		token += "~"
+	}
+
+	var propList []string
+	switch extra := e.ExtraData.(type) {
+	case *IfElemExtra:
+		propList = append(propList, "cond_kind="+extra.CondKind)
+		if extra.StringArg != "" {
+			propList = append(propList, "string_arg="+extra.StringArg)
+		}
+		if extra.IntArg != 0 {
+			propList = append(propList, "int_arg="+strconv.Itoa(extra.IntArg))
+		}
+	case *TransformElemExtra:
+		if info.IsConfigurable() {
+			propList = append(propList, "op="+extra.Op)
+			if extra.Scope != "" {
+				propList = append(propList, "scope="+extra.Scope)
+			}
+			if extra.RangeFrom != 0 || extra.RangeTo != 0 {
+				propList = append(propList,
+					"range_from="+strconv.Itoa(extra.RangeFrom),
+					"range_to="+strconv.Itoa(extra.RangeTo))
+			}
+		}
+	}
+
+	return token, strings.Join(propList, " "), nil
+}
+
+func parseTextCoords(s string) (float64, float64, error) {
+	xs, ys, ok := strings.Cut(strings.TrimSpace(s), ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("%q: expected col,row coordinates", s)
+	}
+	x, err := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q: %w", s, err)
+	}
+	y, err := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q: %w", s, err)
+	}
+	return x, y, nil
+}
+
+var (
+	hintEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	hintUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+)
+
+func escapeHintText(s string) string   { return hintEscaper.Replace(s) }
+func unescapeHintText(s string) string { return hintUnescaper.Replace(s) }
+
+// textPropKinds maps the known props to their "is integer" flag.
+var textPropKinds = map[string]bool{
+	"cond_kind":  false,
+	"string_arg": false,
+	"int_arg":    true,
+	"op":         false,
+	"scope":      false,
+	"range_from": true,
+	"range_to":   true,
+}
@@ -0,0 +1,165 @@
+package leveldata
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+func TestTextTemplate(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	const level = `
+# Even-length inputs go through the special pipe (bottom branch).
+grid:
+.  .  A@270   P SUB/F P A
+IN P  IF      . .     . OUT
+.  .  SA@270~ P ADD/L P A@180~
+settings:
+num_keywords: 1
+keywords: abc ab
+hints:
+1,2.5: hello\nworld
+props:
+2,1: cond_kind=len_even
+`
+	tmpl, err := LoadTextTemplate(tileset, []byte(level))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.NumCols != 7 || tmpl.NumRows != 3 {
+		t.Fatalf("unexpected grid size: %dx%d", tmpl.NumCols, tmpl.NumRows)
+	}
+	wantHints := []SchemaHintTemplate{
+		{Text: "hello\nworld", Pos: gmath.Vec{X: tileset.TileWidth, Y: 2.5 * tileset.TileHeight}},
+	}
+	if !reflect.DeepEqual(tmpl.Hints, wantHints) {
+		t.Fatalf("unexpected hints: %v", tmpl.Hints)
+	}
+
+	// The hint text spaces are preserved.
+	tmpl.Hints = append(tmpl.Hints, SchemaHintTemplate{Text: "  padded\n text ", Pos: gmath.Vec{X: 2 * tileset.TileWidth}})
+	data, err := TemplateToText(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl2, err := LoadTextTemplate(tileset, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tmpl2.Hints, tmpl.Hints) {
+		t.Fatalf("hints are not preserved:\nhave: %q\nwant: %q", tmpl2.Hints, tmpl.Hints)
+	}
+	tmpl.Hints = wantHints
+
+	schema, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := NewSchemaRunner()
+	for input, want := range map[string]string{"abc": "zbc", "ab": "ac"} {
+		have, err := runner.Exec(schema, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have != want {
+			t.Errorf("%q: expected %q, found %q", input, want, have)
+		}
+	}
+}
+
+func TestTextTemplateErrors(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	tests := []struct {
+		level string
+		err   string
+	}{
+		{"settings:\nkeywords: a", "grid section is missing"},
+		{"grid:\nIN P OUT", "settings.keywords is empty"},
+		{"grid:\nIN P OUT\nIN P\nsettings:\nkeywords: a", "line 3: expected 3 columns, found 2"},
+		{"grid:\nIN FOO OUT\nsettings:\nkeywords: a", `line 2: 1,0: "FOO": unknown element code`},
+		{"grid:\nIN P@45 OUT\nsettings:\nkeywords: a", `"P@45": invalid rotation`},
+		{"grid:\nIN P~ OUT\nsettings:\nkeywords: a", `"P~": only angle pipes can be flipped`},
+		{"grid:\nIN IF OUT\nsettings:\nkeywords: a", "1,0: elem_if: cond_kind property is empty"},
+		{"grid:\nIN X OUT\nsettings:\nkeywords: a\nprops:\n1,0: op=add range_from=x", `range_from: expected an integer value`},
+		{"grid:\nIN X OUT\nsettings:\nkeywords: a\nprops:\n1,0: opp=add", `unknown prop "opp"`},
+		{"grid:\nIN . OUT\nsettings:\nkeywords: a\nprops:\n1,0: op=add", "found props for the empty cells: 1,0"},
+	}
+
+	for _, test := range tests {
+		_, err := LoadTextTemplate(tileset, []byte(test.level))
+		if err == nil {
+			t.Errorf("%q: expected an error", test.level)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: unexpected error: %v", test.level, err)
+		}
+	}
+}
+
+func TestTextTemplateLevels(t *testing.T) {
+	tileset := loadTestTileset(t)
+
+	filenames, err := filepath.Glob("../_assets/levels/*/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, filename := range filenames {
+		levelData, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := LoadLevelTemplate(tileset, levelData)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		text, err := TemplateToText(tmpl)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		tmpl2, err := LoadTextTemplate(tileset, text)
+		if err != nil {
+			t.Fatalf("%s: load text:\n%s\n%v", filename, text, err)
+		}
+		text2, err := TemplateToText(tmpl2)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		if string(text) != string(text2) {
+			t.Fatalf("%s: text round trip mismatch:\n%s\nvs\n%s", filename, text, text2)
+		}
+
+		// Both templates should produce the same programs.
+		schema := buildTestSchema(t, tmpl)
+		schema2 := buildTestSchema(t, tmpl2)
+		runner := NewSchemaRunner()
+		for _, k := range schema.Keywords {
+			want, err := runner.Exec(schema, k)
+			if err != nil {
+				t.Fatalf("%s: %q: %v", filename, k, err)
+			}
+			have, err := runner.Exec(schema2, k)
+			if err != nil {
+				t.Fatalf("%s: %q: %v", filename, k, err)
+			}
+			if have != want {
+				t.Fatalf("%s: %q: expected %q, found %q", filename, k, want, have)
+			}
+		}
+	}
+}
+
+func buildTestSchema(t testing.TB, tmpl *SchemaTemplate) *ComponentSchema {
+	t.Helper()
+	schema, err := NewSchemaBuilder(gmath.Vec{}, tmpl).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
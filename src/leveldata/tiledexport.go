@@ -0,0 +1,252 @@
+package leveldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/quasilyte/gmath"
+)
+
+// The types below describe the subset of the Tiled JSON map format
+// that is used by the level files.
+// The fields are ordered in the same way as Tiled does it.
+//
+// The existing maps should not be re-encoded with these types,
+// the fields outside of this subset would be lost (see tiledMapData).
+
+type tiledMapJSON struct {
+	CompressionLevel int                   `json:"compressionlevel"`
+	Height           int                   `json:"height"`
+	Infinite         bool                  `json:"infinite"`
+	Layers           []tiledLayerJSON      `json:"layers"`
+	NextLayerID      int                   `json:"nextlayerid"`
+	NextObjectID     int                   `json:"nextobjectid"`
+	Orientation      string                `json:"orientation"`
+	RenderOrder      string                `json:"renderorder"`
+	TiledVersion     string                `json:"tiledversion"`
+	TileHeight       int                   `json:"tileheight"`
+	Tilesets         []tiledTilesetRefJSON `json:"tilesets"`
+	TileWidth        int                   `json:"tilewidth"`
+	Type             string                `json:"type"`
+	Version          string                `json:"version"`
+	Width            int                   `json:"width"`
+}
+
+type tiledLayerJSON struct {
+	DrawOrder string            `json:"draworder"`
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	Objects   []tiledObjectJSON `json:"objects"`
+	Opacity   float64           `json:"opacity"`
+	Type      string            `json:"type"`
+	Visible   bool              `json:"visible"`
+	X         int               `json:"x"`
+	Y         int               `json:"y"`
+}
+
+type tiledTilesetRefJSON struct {
+	FirstGID int    `json:"firstgid"`
+	Source   string `json:"source"`
+}
+
+type tiledObjectJSON struct {
+	Class      string              `json:"class"`
+	GID        uint32              `json:"gid"`
+	Height     float64             `json:"height"`
+	ID         int                 `json:"id"`
+	Name       string              `json:"name"`
+	Properties []tiledPropertyJSON `json:"properties,omitempty"`
+	Rotation   float64             `json:"rotation"`
+	Visible    bool                `json:"visible"`
+	Width      float64             `json:"width"`
+	X          float64             `json:"x"`
+	Y          float64             `json:"y"`
+}
+
+type tiledPropertyJSON struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+const (
+	tiledFlippedHorizontallyFlag = 0x80000000
+	tiledFlippedVerticallyFlag   = 0x40000000
+	tiledFlippedDiagonallyFlag   = 0x20000000
+
+	tiledFlagsMask = tiledFlippedHorizontallyFlag | tiledFlippedVerticallyFlag | tiledFlippedDiagonallyFlag
+)
+
+// TemplateToTilemap encodes the schema template as a Tiled map.
+//
+// tilesetSource is a tileset file path relative to the map file.
+// The result can be loaded with LoadLevelTemplate.
+func TemplateToTilemap(t *SchemaTemplate, tilesetSource string) ([]byte, error) {
+	tileset := t.Tileset
+	numCols := t.NumCols
+	numRows := t.NumRows
+	if numCols == 0 && numRows == 0 {
+		numCols = DefaultNumSchemaCols
+		numRows = DefaultNumSchemaRows
+	}
+
+	const firstGID = 1
+	var objects []tiledObjectJSON
+	addObject := func(class string, pos gmath.Vec, rotation int, flip bool, props []tiledPropertyJSON) error {
+		id, ok := tileset.tileIDByClass[class]
+		if !ok {
+			return fmt.Errorf("%v: %s: unknown element class", pos, class)
+		}
+		gid := uint32(firstGID + id)
+		if flip {
+			gid |= tiledFlippedHorizontallyFlag
+		}
+		objects = append(objects, tiledObjectJSON{
+			GID:        gid,
+			Height:     tileset.TileHeight,
+			ID:         len(objects) + 1,
+			Properties: props,
+			Rotation:   float64(rotation),
+			Visible:    true,
+			Width:      tileset.TileWidth,
+			X:          pos.X,
+			Y:          pos.Y,
+		})
+		return nil
+	}
+
+	for _, e := range t.Elems {
+		if _, _, err := templateElemCell(t, e); err != nil {
+			return nil, err
+		}
+		rotation := rotationDegrees(e.Rotation)
+		flip := false
+		var props []tiledPropertyJSON
+		switch extra := e.ExtraData.(type) {
+		case *AngleElemExtra:
+			flip = extra.FlipHorizontally
+		case *IfElemExtra:
+			props = append(props, tiledStringProp("cond_kind", extra.CondKind))
+			if extra.IntArg != 0 {
+				props = append(props, tiledIntProp("int_arg", extra.IntArg))
+			}
+			if extra.StringArg != "" {
+				props = append(props, tiledStringProp("string_arg", extra.StringArg))
+			}
+		case *TransformElemExtra:
+			if info := tileset.ElemByClass(e.Class); info != nil && info.IsConfigurable() {
+				props = append(props, tiledStringProp("op", extra.Op))
+				if extra.RangeFrom != 0 {
+					props = append(props, tiledIntProp("range_from", extra.RangeFrom))
+				}
+				if extra.RangeTo != 0 {
+					props = append(props, tiledIntProp("range_to", extra.RangeTo))
+				}
+				if extra.Scope != "" {
+					props = append(props, tiledStringProp("scope", extra.Scope))
+				}
+			}
+		}
+		pos, ok := tiledObjectPos(tileset, e.Pos, rotation)
+		if !ok {
+			return nil, fmt.Errorf("%v: %s: unsupported rotation angle %d", e.Pos, e.Class, rotation)
+		}
+		if err := addObject(e.Class, pos, rotation, flip, props); err != nil {
+			return nil, err
+		}
+	}
+
+	settingsProps := []tiledPropertyJSON{
+		tiledIntProp("format_version", LevelFormatVersion),
+		tiledStringProp("keywords", strings.Join(t.Keywords, "\n")),
+		tiledIntProp("num_keywords", t.NumKeywords),
+	}
+	settingsPos := gmath.Vec{Y: float64(numRows) * tileset.TileHeight}
+	if err := addObject("settings", settingsPos, 0, false, settingsProps); err != nil {
+		return nil, err
+	}
+
+	for _, h := range t.Hints {
+		props := []tiledPropertyJSON{tiledStringProp("text", h.Text)}
+		if err := addObject("hint", h.Pos, 0, false, props); err != nil {
+			return nil, err
+		}
+	}
+
+	m := tiledMapJSON{
+		CompressionLevel: -1,
+		Height:           numRows,
+		Layers: []tiledLayerJSON{
+			{
+				DrawOrder: "topdown",
+				ID:        2,
+				Name:      "scheme",
+				Objects:   objects,
+				Opacity:   1,
+				Type:      "objectgroup",
+				Visible:   true,
+			},
+		},
+		NextLayerID:  3,
+		NextObjectID: len(objects) + 1,
+		Orientation:  "orthogonal",
+		RenderOrder:  "right-down",
+		TiledVersion: "1.9.2",
+		TileHeight:   int(tileset.TileHeight),
+		Tilesets:     []tiledTilesetRefJSON{{FirstGID: firstGID, Source: tilesetSource}},
+		TileWidth:    int(tileset.TileWidth),
+		Type:         "map",
+		Version:      "1.9",
+		Width:        numCols,
+	}
+	return json.MarshalIndent(m, "", " ")
+}
+
+func tiledStringProp(name, value string) tiledPropertyJSON {
+	return tiledPropertyJSON{Name: name, Type: "string", Value: value}
+}
+
+func tiledIntProp(name string, value int) tiledPropertyJSON {
+	return tiledPropertyJSON{Name: name, Type: "int", Value: value}
+}
+
+// tiledObjectPos returns a Tiled object position for the element cell center.
+// It's an inverse of the TilemapToTemplate calcObjectPos.
+//
+// Tiled objects are rotated around their bottom-left corner,
+// so the object position depends on its rotation.
+func tiledObjectPos(tileset *Tileset, center gmath.Vec, rotation int) (gmath.Vec, bool) {
+	halfWidth := tileset.TileWidth / 2
+	halfHeight := tileset.TileHeight / 2
+	switch rotation {
+	case 0:
+		return center.Add(gmath.Vec{X: -halfWidth, Y: halfHeight}), true
+	case 90:
+		return center.Add(gmath.Vec{X: -halfWidth, Y: -halfHeight}), true
+	case 180:
+		return center.Add(gmath.Vec{X: halfWidth, Y: -halfHeight}), true
+	case 270:
+		return center.Add(gmath.Vec{X: halfWidth, Y: halfHeight}), true
+	default:
+		return gmath.Vec{}, false
+	}
+}
+
+// tiledObjectCenter is an inverse of tiledObjectPos.
+func tiledObjectCenter(tileset *Tileset, pos gmath.Vec, rotation int) (gmath.Vec, bool) {
+	halfWidth := tileset.TileWidth / 2
+	halfHeight := tileset.TileHeight / 2
+	switch rotation {
+	case 0:
+		return pos.Add(gmath.Vec{X: halfWidth, Y: -halfHeight}), true
+	case 90:
+		return pos.Add(gmath.Vec{X: halfWidth, Y: halfHeight}), true
+	case 180:
+		return pos.Add(gmath.Vec{X: -halfWidth, Y: halfHeight}), true
+	case 270:
+		return pos.Add(gmath.Vec{X: -halfWidth, Y: -halfHeight}), true
+	default:
+		return gmath.Vec{}, false
+	}
+}
@@ -0,0 +1,252 @@
+package leveldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/quasilyte/ge/tiled"
+)
+
+// Tileset is a schemas.tsj tileset along with the schema elements metadata.
+//
+// The metadata is stored as tile properties:
+//
+//	kind  - an element kind, like "pipe", "if" or "transform" (tiles without it are not elements)
+//	op    - a transform operation (only for the transform elements)
+//	scope - a transform scope modifier, like "butfirst" or "odd" (optional)
+//	tags  - a comma-separated list of features, like "incdec" or "negation" (optional)
+//
+// A transform tile with an empty op is configurable: every object
+// of that tile specifies its own op, scope and range (see TransformElemExtra).
+type Tileset struct {
+	*tiled.Tileset
+
+	elems       []*ElemInfo
+	elemByClass map[string]*ElemInfo
+	elemByCode  map[string]*ElemInfo
+
+	// tileIDByClass is used to encode the Tiled maps.
+	tileIDByClass map[string]int
+}
+
+// ElemInfo describes a schema element tile.
+type ElemInfo struct {
+	Class string
+	Kind  SchemaElemKind
+
+	// TileID is an ID of this tile inside the tileset.
+	TileID int
+
+	// Code is a short element name that is used in the text format.
+	Code string
+
+	// Sprite is an element image path, relative to the tileset file.
+	Sprite string
+
+	// Tags is a list of features this element uses.
+	// Every tag is one of the FeatureTags.
+	Tags []string
+
+	// Transform is a default transform of this element.
+	// It's only set for the TransformElem kind.
+	// Configurable transforms have it set to nil.
+	Transform *TransformElemExtra
+}
+
+// IsConfigurable reports whether this element transform is defined by the object properties.
+func (info *ElemInfo) IsConfigurable() bool {
+	return info.Kind == TransformElem && info.Transform == nil
+}
+
+// FeatureTags lists all known element feature tags.
+var FeatureTags = []string{
+	"cond_transform",
+	"polygraphic",
+	"atbash",
+	"rot13",
+	"incdec",
+	"shift",
+	"negation",
+}
+
+type tilesetJSON struct {
+	Tiles []struct {
+		ID         int    `json:"id"`
+		Class      string `json:"class"`
+		Image      string `json:"image"`
+		Properties []struct {
+			Name  string `json:"name"`
+			Value any    `json:"value"`
+		} `json:"properties"`
+	} `json:"tiles"`
+}
+
+func LoadTileset(data []byte) (*Tileset, error) {
+	tileset, err := tiled.UnmarshalTileset(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tile properties are decoded separately.
+	var raw tilesetJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	result := &Tileset{
+		Tileset:     tileset,
+		elemByClass: make(map[string]*ElemInfo, len(raw.Tiles)),
+		elemByCode:  make(map[string]*ElemInfo, len(raw.Tiles)),
+
+		tileIDByClass: make(map[string]int, len(raw.Tiles)),
+	}
+	for _, t := range raw.Tiles {
+		result.tileIDByClass[t.Class] = t.ID
+		props := make(map[string]string, len(t.Properties))
+		for _, p := range t.Properties {
+			if s, ok := p.Value.(string); ok {
+				props[p.Name] = s
+			}
+		}
+		if props["kind"] == "" {
+			// Not an element (settings, hints, etc).
+			continue
+		}
+		kind, ok := elemKindByName[props["kind"]]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown element kind %q", t.Class, props["kind"])
+		}
+		info := &ElemInfo{
+			Class:  t.Class,
+			Kind:   kind,
+			TileID: t.ID,
+			Sprite: t.Image,
+		}
+		if tags := props["tags"]; tags != "" {
+			for _, tag := range strings.Split(tags, ",") {
+				tag = strings.TrimSpace(tag)
+				if !isFeatureTag(tag) {
+					return nil, fmt.Errorf("%s: unknown feature tag %q", t.Class, tag)
+				}
+				info.Tags = append(info.Tags, tag)
+			}
+		}
+		if kind == TransformElem {
+			if props["op"] != "" {
+				transform, err := NewTransformElemExtra(props["op"], props["scope"], 0, 0)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", t.Class, err)
+				}
+				info.Transform = transform
+			}
+		} else if props["op"] != "" || props["scope"] != "" {
+			return nil, fmt.Errorf("%s: only transform elements can have transform properties", t.Class)
+		}
+		info.Code = elemCode(info)
+		if other := result.elemByCode[info.Code]; other != nil {
+			return nil, fmt.Errorf("%s: code %q is already used by %s", t.Class, info.Code, other.Class)
+		}
+		result.elems = append(result.elems, info)
+		result.elemByClass[info.Class] = info
+		result.elemByCode[info.Code] = info
+	}
+
+	return result, nil
+}
+
+// transformTags returns the feature tags for a configurable transform.
+//
+// The tags are borrowed from the tile that performs the same op without a scope.
+// Filtered scopes make it a conditional transformation.
+func (ts *Tileset) transformTags(extra *TransformElemExtra) []string {
+	if extra.filter != filterNone {
+		return []string{"cond_transform"}
+	}
+	for _, info := range ts.elems {
+		if info.Transform != nil && info.Transform.Op == extra.Op && info.Transform.Scope == "" {
+			return info.Tags
+		}
+	}
+	return nil
+}
+
+// Elems returns all element tiles, in the tileset order.
+func (ts *Tileset) Elems() []*ElemInfo {
+	return ts.elems
+}
+
+// ElemByClass returns an element info for the given tile class.
+// It returns nil if there is no such element.
+func (ts *Tileset) ElemByClass(class string) *ElemInfo {
+	return ts.elemByClass[class]
+}
+
+// ElemByCode returns an element info for the given text format code.
+// It returns nil if there is no such element.
+func (ts *Tileset) ElemByCode(code string) *ElemInfo {
+	return ts.elemByCode[code]
+}
+
+var elemCodeByClass = map[string]string{
+	"pipe":               "P",
+	"special_pipe":       "SP",
+	"angle_pipe":         "A",
+	"special_angle_pipe": "SA",
+	"pipe_connect2":      "J",
+	"elem_input":         "IN",
+	"elem_output":        "OUT",
+	"elem_mux":           "MUX",
+	"elem_if":            "IF",
+	"elem_ifnot":         "IFN",
+	"elem_repeater":      "REP",
+	"elem_inv_repeater":  "NREP",
+	"elem_countdown0":    "CD0",
+	"elem_countdown1":    "CD1",
+	"elem_countdown2":    "CD2",
+	"elem_countdown3":    "CD3",
+}
+
+var scopeCodeByName = map[string]string{
+	"first":    "F",
+	"last":     "L",
+	"butfirst": "BF",
+	"butlast":  "BL",
+	"odd":      "O",
+	"even":     "E",
+	"dotted":   "D",
+	"undotted": "U",
+}
+
+// elemCode returns a text format code for the element.
+//
+// Transform codes are derived from their op and scope,
+// like "ADD/BF" for the "add" op with the "butfirst" scope.
+// A configurable transform is coded as "X".
+func elemCode(info *ElemInfo) string {
+	if code, ok := elemCodeByClass[info.Class]; ok {
+		return code
+	}
+	if info.Kind != TransformElem {
+		return strings.ToUpper(info.Class)
+	}
+	if info.IsConfigurable() {
+		return "X"
+	}
+	code := info.Transform.op.code
+	if info.Transform.Scope != "" {
+		for _, part := range strings.Split(info.Transform.Scope, "_") {
+			code += "/" + scopeCodeByName[part]
+		}
+	}
+	return code
+}
+
+func isFeatureTag(tag string) bool {
+	for _, t := range FeatureTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package leveldata
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadTileset(t *testing.T) {
+	tileset, err := LoadTileset([]byte(`{
+		"tilewidth": 96,
+		"tileheight": 96,
+		"tiles": [
+			{"id": 20, "class": "settings"},
+			{"id": 7, "class": "angle_pipe", "properties": [
+				{"name": "kind", "type": "string", "value": "pipe"}
+			]},
+			{"id": 3, "class": "my_reverse", "properties": [
+				{"name": "kind", "type": "string", "value": "transform"},
+				{"name": "op", "type": "string", "value": "reverse"}
+			]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tileset.Elems()) != 2 {
+		t.Fatalf("expected 2 elements, found %d", len(tileset.Elems()))
+	}
+	pipe := tileset.ElemByClass("angle_pipe")
+	if pipe.Kind != SimplePipeElem || pipe.TileID != 7 {
+		t.Fatalf("unexpected angle_pipe info: %+v", pipe)
+	}
+	// The kind is not derived from the class name.
+	transform := tileset.ElemByClass("my_reverse")
+	if transform.Kind != TransformElem || transform.TileID != 3 || transform.Transform.Op != "reverse" {
+		t.Fatalf("unexpected my_reverse info: %+v", transform)
+	}
+
+	_, err = LoadTileset([]byte(`{"tiles": [
+		{"id": 1, "class": "elem_if", "properties": [
+			{"name": "kind", "type": "string", "value": "cond"}
+		]}
+	]}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown element kind "cond"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package leveldata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quasilyte/gmath"
+)
+
+// transformOp is an operation that can be applied to the data.
+//
+// Char-wise ops define a char func, others define an apply func.
+type transformOp struct {
+	char  func(ch byte) byte
+	apply func(data []byte)
+
+	// code is used to form the element codes for the text format.
+	code string
+
+	// label is a short op description that is displayed on the element.
+	label string
+
+	// inverse is a name of the operation that reverts this one.
+	// It's empty for the lossy operations.
+	inverse string
+
+	// permutation ops only move the letters around.
+	permutation bool
+}
+
+var transformOpByName = map[string]*transformOp{
+	"reverse":      {apply: reverseChars, code: "REV", label: "REV", inverse: "reverse", permutation: true},
+	"swap_halves":  {apply: swapHalves, code: "SWAP", label: "SWAP", inverse: "swap_halves", permutation: true},
+	"zigzag":       {apply: zigzagChars, code: "ZZ", label: "ZIGZAG", inverse: "zigzag", permutation: true},
+	"rotate_left":  {apply: RotateCharsLeft, code: "ROTL", label: "ROT <", inverse: "rotate_right", permutation: true},
+	"rotate_right": {apply: RotateCharsRight, code: "ROTR", label: "ROT >", inverse: "rotate_left", permutation: true},
+
+	"polygraphic_atbash": {apply: polygraphicAtbash, code: "PATB", label: "P.ATB"},
+
+	"add":             {char: IncChar, code: "ADD", label: "+1", inverse: "sub"},
+	"sub":             {char: DecChar, code: "SUB", label: "-1", inverse: "add"},
+	"add_nowrap":      {char: incCharNowrap, code: "ADDN", label: "+1 NW"},
+	"sub_nowrap":      {char: decCharNowrap, code: "SUBN", label: "-1 NW"},
+	"rot13":           {char: rot13Char, code: "R13", label: "ROT13", inverse: "rot13"},
+	"atbash":          {char: atbashChar, code: "ATB", label: "ATBASH", inverse: "atbash"},
+	"hardshift_left":  {char: hardshiftLeftChar, code: "HSL", label: "HS <"},
+	"hardshift_right": {char: hardshiftRightChar, code: "HSR", label: "HS >"},
+}
+
+type scopeRange int
+
+const (
+	scopeAll scopeRange = iota
+	scopeFirst
+	scopeLast
+	scopeButfirst
+	scopeButlast
+	scopeRangeFromTo
+)
+
+type scopeFilter int
+
+const (
+	filterNone scopeFilter = iota
+	filterOdd
+	filterEven
+	filterDotted
+	filterUndotted
+)
+
+var scopeRangeByName = map[string]scopeRange{
+	"first":    scopeFirst,
+	"last":     scopeLast,
+	"butfirst": scopeButfirst,
+	"butlast":  scopeButlast,
+	"range":    scopeRangeFromTo,
+}
+
+var scopeFilterByName = map[string]scopeFilter{
+	"odd":      filterOdd,
+	"even":     filterEven,
+	"dotted":   filterDotted,
+	"undotted": filterUndotted,
+}
+
+// TransformElemExtra describes what a transform element does.
+//
+// Op is an operation name, like "add" or "reverse".
+// Scope selects the letters this operation is applied to.
+// An empty scope means "all letters"; otherwise it's a range ("first",
+// "last", "butfirst", "butlast", "range"), a filter ("odd", "even", "dotted", "undotted")
+// or both joined with "_", like "butfirst_dotted".
+// Odd and even positions are counted from 1 inside the selected range.
+//
+// The "range" scope selects the letters from RangeFrom to RangeTo (inclusive).
+// Positions are counted from 1; the letters beyond the data length are ignored.
+type TransformElemExtra struct {
+	Op    string
+	Scope string
+
+	RangeFrom int
+	RangeTo   int
+
+	op      *transformOp
+	inverse *transformOp
+	rng     scopeRange
+	filter  scopeFilter
+}
+
+func NewTransformElemExtra(op, scope string, rangeFrom, rangeTo int) (*TransformElemExtra, error) {
+	extra := &TransformElemExtra{
+		Op:        op,
+		Scope:     scope,
+		RangeFrom: rangeFrom,
+		RangeTo:   rangeTo,
+	}
+	if op == "" {
+		return nil, fmt.Errorf("transform op is empty")
+	}
+	extra.op = transformOpByName[op]
+	if extra.op == nil {
+		return nil, fmt.Errorf("unknown transform op %q", op)
+	}
+	if extra.op.inverse != "" {
+		extra.inverse = transformOpByName[extra.op.inverse]
+	}
+
+	if scope != "" {
+		hasRange := false
+		hasFilter := false
+		for _, part := range strings.Split(scope, "_") {
+			if rng, ok := scopeRangeByName[part]; ok && !hasRange {
+				extra.rng = rng
+				hasRange = true
+				continue
+			}
+			if filter, ok := scopeFilterByName[part]; ok && !hasFilter {
+				extra.filter = filter
+				hasFilter = true
+				continue
+			}
+			return nil, fmt.Errorf("invalid transform scope %q", scope)
+		}
+	}
+
+	if extra.rng == scopeRangeFromTo {
+		if rangeFrom < 1 || rangeTo > MaxInputLen || rangeFrom > rangeTo {
+			return nil, fmt.Errorf("invalid transform range [%d, %d]: expected 1 <= from <= to <= %d",
+				rangeFrom, rangeTo, MaxInputLen)
+		}
+	} else if rangeFrom != 0 || rangeTo != 0 {
+		return nil, fmt.Errorf("transform range is only allowed for the \"range\" scope")
+	}
+
+	return extra, nil
+}
+
+// IsInvertible reports whether this transform can be reversed.
+// For any output of an invertible transform, there is exactly one input that produces it.
+func (t *TransformElemExtra) IsInvertible() bool {
+	if t.inverse == nil {
+		return false
+	}
+	switch t.filter {
+	case filterDotted, filterUndotted:
+		// The letters can change their dotted status after
+		// the transformation, so we can't tell which ones were affected.
+		// Permutations are OK as they don't change the letters.
+		return t.op.permutation
+	default:
+		return true
+	}
+}
+
+// Label returns a multi-line transform description, like "+1\nBUTFIRST".
+// It's used to display the configurable transforms.
+func (t *TransformElemExtra) Label() string {
+	lines := []string{t.op.label}
+	if t.Scope != "" {
+		for _, part := range strings.Split(t.Scope, "_") {
+			if part == "range" {
+				lines = append(lines, fmt.Sprintf("%d..%d", t.RangeFrom, t.RangeTo))
+				continue
+			}
+			lines = append(lines, strings.ToUpper(part))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (t *TransformElemExtra) apply(data []byte) {
+	t.applyOp(t.op, data)
+}
+
+func (t *TransformElemExtra) applyInverse(data []byte) {
+	t.applyOp(t.inverse, data)
+}
+
+func (t *TransformElemExtra) applyOp(op *transformOp, data []byte) {
+	data = t.selectRange(data)
+
+	if t.filter == filterNone {
+		if op.char != nil {
+			mapChars(data, op.char)
+		} else {
+			op.apply(data)
+		}
+		return
+	}
+
+	if op.char != nil {
+		for i, ch := range data {
+			if t.matchFilter(i, ch) {
+				data[i] = op.char(ch)
+			}
+		}
+		return
+	}
+
+	// Collect the filtered letters, apply the op to them
+	// and then put them back to their original positions.
+	var indexes []int
+	var selected []byte
+	for i, ch := range data {
+		if t.matchFilter(i, ch) {
+			indexes = append(indexes, i)
+			selected = append(selected, ch)
+		}
+	}
+	op.apply(selected)
+	for i, j := range indexes {
+		data[j] = selected[i]
+	}
+}
+
+func (t *TransformElemExtra) selectRange(data []byte) []byte {
+	if len(data) == 0 {
+		return data
+	}
+	switch t.rng {
+	case scopeFirst:
+		return data[:1]
+	case scopeLast:
+		return data[len(data)-1:]
+	case scopeButfirst:
+		return data[1:]
+	case scopeButlast:
+		return data[:len(data)-1]
+	case scopeRangeFromTo:
+		from := t.RangeFrom - 1
+		if from >= len(data) {
+			return data[:0]
+		}
+		return data[from:gmath.ClampMax(t.RangeTo, len(data))]
+	default:
+		return data
+	}
+}
+
+func (t *TransformElemExtra) matchFilter(i int, ch byte) bool {
+	switch t.filter {
+	case filterOdd:
+		return (i+1)%2 != 0
+	case filterEven:
+		return (i+1)%2 == 0
+	case filterDotted:
+		return dottedChars[ch]
+	case filterUndotted:
+		return !dottedChars[ch]
+	default:
+		return true
+	}
+}
@@ -87,79 +87,12 @@ const (
 	ImageCompleteMark
 	ImagePipelineArrow
 	componentSchemaImageOffset
-	ImagePipeConnect2
-	ImageSpecialAnglePipe
-	ImageAnglePipe
-	ImageSpecialPipe
-	ImagePipe
-	ImageElemInput
-	ImageElemOutput
-	ImageElemMux
-	ImageElemIf
-	ImageElemIfNot
-	ImageElemRepeater
-	ImageElemInvRepeater
-	ImageElemCountdown0
-	ImageElemCountdown1
-	ImageElemCountdown2
-	ImageElemCountdown3
-	ImageElemReverse
-	ImageElemSwapHalves
-	ImageElemRotateLeft
-	ImageElemRotateLeftButfirst
-	ImageElemRotateRight
-	ImageElemRotateRightButfirst
-	ImageElemAdd
-	ImageElemAddButfirst
-	ImageElemAddFirst
-	ImageElemAddLast
-	ImageElemAddNowrap
-	ImageElemAddButfirstNowrap
-	ImageElemAddDotted
-	ImageElemAddButfirstDotted
-	ImageElemAddEven
-	ImageElemAddOdd
-	ImageElemSub
-	ImageElemSubButlast
-	ImageElemSubFirst
-	ImageElemSubLast
-	ImageElemSubNowrap
-	ImageElemSubUndotted
-	ImageElemSubEven
-	ImageElemSubOdd
-	ImageElemAtbash
-	ImageElemAtbashButlast
-	ImageElemAtbashFirst
-	ImageElemPolygraphicAtbash
-	ImageElemRot13
-	ImageElemRot13Butfirst
-	ImageElemRot13Butlast
-	ImageElemRot13First
-	ImageElemHardshiftLeft
-	ImageElemHardshiftRight
-	ImageElemZigzag
 )
 
 const (
 	RawNone resource.RawID = iota
 	RawComponentSchemaTilesetJSON
-	rawLastID
-	RawLevel1JSON
-	RawLevel2JSON
-	RawLevel3JSON
-	RawLevel4JSON
-	RawLevel5JSON
-	RawLevel6JSON
-	RawLevel7JSON
-	RawLevel8JSON
-	RawLevel9JSON
-	RawLevel10JSON
-	RawLevel11JSON
-	RawLevel12JSON
-	RawLevel13JSON
-	RawLevel14JSON
-	RawLevel15JSON
-	RawLevel16JSON
+	RawGeneratorWordsTXT
 )
 
 const (
@@ -191,6 +124,10 @@ const (
 	ActionCharDec
 	ActionRotateLeft
 	ActionRotateRight
+	ActionScrollLeft
+	ActionScrollRight
+	ActionScrollUp
+	ActionScrollDown
 )
 
 const (
@@ -311,75 +248,25 @@ func main() {
 		ImageChapterSelectOutline: {Path: "chapter_select_outline.png"},
 		ImageCompleteMark:         {Path: "complete_mark.png"},
 		ImagePipelineArrow:        {Path: "pipeline_arrow.png"},
-
-		ImagePipeConnect2:            {Path: "elements/pipe_connect2.png"},
-		ImageSpecialAnglePipe:        {Path: "elements/special_angle_pipe.png"},
-		ImageAnglePipe:               {Path: "elements/angle_pipe.png"},
-		ImagePipe:                    {Path: "elements/pipe.png"},
-		ImageSpecialPipe:             {Path: "elements/special_pipe.png"},
-		ImageElemInput:               {Path: "elements/elem_input.png"},
-		ImageElemOutput:              {Path: "elements/elem_output.png"},
-		ImageElemMux:                 {Path: "elements/elem_mux.png"},
-		ImageElemIf:                  {Path: "elements/elem_if.png"},
-		ImageElemIfNot:               {Path: "elements/elem_ifnot.png"},
-		ImageElemRepeater:            {Path: "elements/elem_repeater.png"},
-		ImageElemInvRepeater:         {Path: "elements/elem_inv_repeater.png"},
-		ImageElemCountdown0:          {Path: "elements/elem_countdown0.png"},
-		ImageElemCountdown1:          {Path: "elements/elem_countdown1.png"},
-		ImageElemCountdown2:          {Path: "elements/elem_countdown2.png"},
-		ImageElemCountdown3:          {Path: "elements/elem_countdown3.png"},
-		ImageElemReverse:             {Path: "elements/elem_reverse.png"},
-		ImageElemSwapHalves:          {Path: "elements/elem_swap_halves.png"},
-		ImageElemRotateLeft:          {Path: "elements/elem_rotate_left.png"},
-		ImageElemRotateLeftButfirst:  {Path: "elements/elem_rotate_left_butfirst.png"},
-		ImageElemRotateRight:         {Path: "elements/elem_rotate_right.png"},
-		ImageElemRotateRightButfirst: {Path: "elements/elem_rotate_right_butfirst.png"},
-		ImageElemAdd:                 {Path: "elements/elem_add.png"},
-		ImageElemAddButfirst:         {Path: "elements/elem_add_butfirst.png"},
-		ImageElemAddFirst:            {Path: "elements/elem_add_first.png"},
-		ImageElemAddLast:             {Path: "elements/elem_add_last.png"},
-		ImageElemAddNowrap:           {Path: "elements/elem_add_nowrap.png"},
-		ImageElemAddButfirstNowrap:   {Path: "elements/elem_add_butfirst_nowrap.png"},
-		ImageElemAddButfirstDotted:   {Path: "elements/elem_add_butfirst_dotted.png"},
-		ImageElemAddDotted:           {Path: "elements/elem_add_dotted.png"},
-		ImageElemAddEven:             {Path: "elements/elem_add_even.png"},
-		ImageElemAddOdd:              {Path: "elements/elem_add_odd.png"},
-		ImageElemSub:                 {Path: "elements/elem_sub.png"},
-		ImageElemSubButlast:          {Path: "elements/elem_sub_butlast.png"},
-		ImageElemSubFirst:            {Path: "elements/elem_sub_first.png"},
-		ImageElemSubLast:             {Path: "elements/elem_sub_last.png"},
-		ImageElemSubNowrap:           {Path: "elements/elem_sub_nowrap.png"},
-		ImageElemSubUndotted:         {Path: "elements/elem_sub_undotted.png"},
-		ImageElemSubEven:             {Path: "elements/elem_sub_even.png"},
-		ImageElemSubOdd:              {Path: "elements/elem_sub_odd.png"},
-		ImageElemAtbash:              {Path: "elements/elem_atbash.png"},
-		ImageElemAtbashButlast:       {Path: "elements/elem_atbash_butlast.png"},
-		ImageElemAtbashFirst:         {Path: "elements/elem_atbash_first.png"},
-		ImageElemPolygraphicAtbash:   {Path: "elements/elem_polygraphic_atbash.png"},
-		ImageElemRot13:               {Path: "elements/elem_rot13.png"},
-		ImageElemRot13Butfirst:       {Path: "elements/elem_rot13_butfirst.png"},
-		ImageElemRot13Butlast:        {Path: "elements/elem_rot13_butlast.png"},
-		ImageElemRot13First:          {Path: "elements/elem_rot13_first.png"},
-		ImageElemHardshiftLeft:       {Path: "elements/elem_hardshift_left.png"},
-		ImageElemHardshiftRight:      {Path: "elements/elem_hardshift_right.png"},
-		ImageElemZigzag:              {Path: "elements/elem_zigzag.png"},
 	}
 	for id, res := range imageResources {
 		ctx.Loader.ImageRegistry.Set(id, res)
 		ctx.Loader.LoadImage(id)
 	}
 
-	prepareAssets(ctx)
-
 	// Associate other resources.
 	rawResources := map[resource.RawID]resource.RawInfo{
 		RawComponentSchemaTilesetJSON: {Path: "schemas.tsj"},
+		RawGeneratorWordsTXT:          {Path: "words.txt"},
 	}
 	for id, res := range rawResources {
 		ctx.Loader.RawRegistry.Set(id, res)
 		ctx.Loader.LoadRaw(id)
 	}
 
+	prepareAssets(ctx)
+	state.campaign = theStoryModeMap
+
 	// Associate shader resources.
 	shaderResources := map[resource.ShaderID]resource.ShaderInfo{
 		ShaderVideoDistortion: {Path: "shader/video_distortion.go"},
@@ -421,6 +308,10 @@ func main() {
 		ActionRotateLeft:        {input.KeyWithModifier(input.KeyLeft, input.ModControl)},
 		ActionRotateRight:       {input.KeyWithModifier(input.KeyRight, input.ModControl)},
 		ActionClearStage:        {input.KeyWithModifier(input.KeyBackquote, input.ModShift)},
+		ActionScrollLeft:        {input.KeyWithModifier(input.KeyLeft, input.ModShift)},
+		ActionScrollRight:       {input.KeyWithModifier(input.KeyRight, input.ModShift)},
+		ActionScrollUp:          {input.KeyWithModifier(input.KeyUp, input.ModShift)},
+		ActionScrollDown:        {input.KeyWithModifier(input.KeyDown, input.ModShift)},
 	}
 	state.input = ctx.Input.NewHandler(0, keymap)
 
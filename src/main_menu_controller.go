@@ -42,9 +42,10 @@ func (c *mainMenuController) Init(scene *ge.Scene) {
 
   -  Review  the  notes
 
-  -  Adjust  the  options`
+  -  Adjust  the  options
+
+  -  Run  a  simulation`
 	if runtime.GOARCH != "wasm" {
-		l.Text += "\n\n  -  Run  a  custom  simulation"
 		l.Text += "\n\n  -  Call  it  a  day"
 	}
 	l.Pos.Offset = offset
@@ -71,6 +72,7 @@ func (c *mainMenuController) initUI(offset gmath.Vec) {
 	bgroup.AddButton(storyModeButton)
 	storyModeButton.Pos.Offset = offset
 	storyModeButton.EventActivated.Connect(nil, func(_ *ui.Button) {
+		c.gameState.campaign = theStoryModeMap
 		c.scene.Context().ChangeScene(newChapterSelectController(c.gameState))
 	})
 	c.scene.AddObject(storyModeButton)
@@ -97,16 +99,17 @@ func (c *mainMenuController) initUI(offset gmath.Vec) {
 
 	offset.Y += 166
 
-	if runtime.GOARCH != "wasm" {
-		customModeButton := uiRoot.NewButton(outlineButtonStyle.Resized(848, 80))
-		bgroup.AddButton(customModeButton)
-		customModeButton.Pos.Offset = offset
-		customModeButton.EventActivated.Connect(nil, func(_ *ui.Button) {
-			c.scene.Context().ChangeScene(newCustomLevelSelectController(c.gameState))
-		})
-		c.scene.AddObject(customModeButton)
-		offset.Y += 166
+	simulationButton := uiRoot.NewButton(outlineButtonStyle.Resized(600, 80))
+	bgroup.AddButton(simulationButton)
+	simulationButton.Pos.Offset = offset
+	simulationButton.EventActivated.Connect(nil, func(_ *ui.Button) {
+		c.scene.Context().ChangeScene(newSimulationMenuController(c.gameState))
+	})
+	c.scene.AddObject(simulationButton)
 
+	offset.Y += 166
+
+	if runtime.GOARCH != "wasm" {
 		exitButton := uiRoot.NewButton(outlineButtonStyle.Resized(460, 80))
 		bgroup.AddButton(exitButton)
 		exitButton.Pos.Offset = offset
@@ -37,7 +37,7 @@ func (c *manualController) Init(scene *ge.Scene) {
 		scene.Audio().ContinueMusic(AudioMenuMusic)
 	}
 
-	content := calculateContentStatus(c.gameState)
+	content := calculateContentStatus(c.gameState, theStoryModeMap)
 	for _, p := range theGameManual.pages {
 		if xslices.Contains(content.manualPages, p.title) {
 			c.pagesAvailable = append(c.pagesAvailable, p)
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/gmath"
+)
+
+type puzzleResultsController struct {
+	gameState *gameState
+	scene     *ge.Scene
+
+	puzzle    generatedPuzzle
+	elapsed   time.Duration
+	newRecord bool
+}
+
+func newPuzzleResultsController(s *gameState, puzzle generatedPuzzle, elapsed time.Duration, newRecord bool) *puzzleResultsController {
+	return &puzzleResultsController{
+		gameState: s,
+		puzzle:    puzzle,
+		elapsed:   elapsed,
+		newRecord: newRecord,
+	}
+}
+
+func (c *puzzleResultsController) Init(scene *ge.Scene) {
+	c.scene = scene
+
+	ctx := scene.Context()
+	rect := ge.NewRect(ctx, ctx.WindowWidth, ctx.WindowWidth)
+	rect.Centered = false
+	rect.FillColorScale.SetRGBA(0x14, 0x18, 0x13, 0xff)
+	scene.AddGraphics(rect)
+
+	puzzles := &c.gameState.data.Puzzles
+
+	var textLines []string
+	if c.puzzle.mode == puzzleDaily {
+		textLines = append(textLines, "success! the "+c.puzzle.date+" daily puzzle is solved")
+	} else {
+		textLines = append(textLines, fmt.Sprintf("success! the level %d puzzle is solved", c.puzzle.difficulty))
+	}
+
+	textLines = append(textLines, "\n> time: "+formatPuzzleTime(c.elapsed))
+	if c.newRecord {
+		textLines = append(textLines, "> this is a new best time")
+	} else {
+		textLines = append(textLines, "> best time: "+formatPuzzleTime(puzzles.bestTime(&c.puzzle)))
+	}
+
+	if c.puzzle.mode == puzzleDaily {
+		textLines = append(textLines, fmt.Sprintf("> daily streak: %d (best: %d)", puzzles.DailyStreak, puzzles.BestDailyStreak))
+		textLines = append(textLines, "\npress 'enter' to continue")
+	} else {
+		textLines = append(textLines, fmt.Sprintf("> endless streak: %d (best: %d)", puzzles.EndlessStreak, puzzles.BestEndlessStreak))
+		textLines = append(textLines, "\npress 'enter' to get the next puzzle")
+		textLines = append(textLines, "\npress 'escape' to take a break")
+	}
+
+	l := scene.NewLabel(FontLCDNormal)
+	l.ColorScale.SetColor(defaultLCDColor)
+	l.Pos.Offset = gmath.Vec{X: 64, Y: 64}
+	l.Text = strings.Join(textLines, "\n")
+	scene.AddGraphics(l)
+}
+
+func (c *puzzleResultsController) Update(delta float64) {
+	if c.gameState.input.ActionIsJustPressed(ActionMenuConfirm) {
+		c.scene.Audio().PauseCurrentMusic()
+		if c.puzzle.mode == puzzleEndless {
+			startPuzzle(c.scene, c.gameState, newEndlessPuzzle(&c.gameState.data.Puzzles, c.scene.Rand()))
+		} else {
+			c.scene.Context().ChangeScene(newSimulationMenuController(c.gameState))
+		}
+		return
+	}
+	if c.gameState.input.ActionIsJustPressed(ActionLeave) {
+		c.scene.Audio().PauseCurrentMusic()
+		c.scene.Context().ChangeScene(newSimulationMenuController(c.gameState))
+	}
+}
@@ -39,10 +39,11 @@ func (c *resultsController) Init(scene *ge.Scene) {
 		}
 	}
 
-	percent := gmath.Percentage(len(c.gameState.data.CompletedLevels), len(theStoryModeMap.levels))
-	textLines = append(textLines, fmt.Sprintf("success! hexagon is now %d%% hacked", percent))
+	campaign := c.gameState.campaign
+	percent := gmath.Percentage(len(c.gameState.CompletedLevels(campaign)), len(campaign.levels))
+	textLines = append(textLines, fmt.Sprintf("success! %s is now %d%% hacked", campaign.title, percent))
 
-	newContent := calculateContentStatus(c.gameState)
+	newContent := calculateContentStatus(c.gameState, campaign)
 
 	newFeatures := xslices.Diff(c.gameState.content.techLevelFeatures, newContent.techLevelFeatures)
 	if len(newFeatures) > 1 {
@@ -2,13 +2,13 @@ package main
 
 import (
 	"github.com/quasilyte/decipherism-game/leveldata"
-	resource "github.com/quasilyte/ebitengine-resource"
 	"github.com/quasilyte/ge"
 	"github.com/quasilyte/gmath"
 )
 
 type schemaElemNode struct {
 	data *leveldata.SchemaElem
+	view *schemaView
 
 	rotation gmath.Rad
 
@@ -19,25 +19,39 @@ type schemaElemNode struct {
 	shaderStep      int
 
 	sprite *ge.Sprite
+	label  *ge.Label
 }
 
-func newSchemaElemNode(data *leveldata.SchemaElem, shaderEnabled bool) *schemaElemNode {
+func newSchemaElemNode(data *leveldata.SchemaElem, view *schemaView, shaderEnabled bool) *schemaElemNode {
 	return &schemaElemNode{
 		data:          data,
+		view:          view,
 		rotation:      data.Rotation,
 		shaderEnabled: shaderEnabled,
 	}
 }
 
 func (n *schemaElemNode) Init(scene *ge.Scene) {
-	imageID := resource.ImageID(n.data.TileClassID) + componentSchemaImageOffset + 1
-	n.sprite = scene.NewSprite(imageID)
+	n.sprite = scene.NewSprite(schemaElemImageID(n.data.TileClassID))
 	n.sprite.Pos.Base = &n.data.Pos
 	n.sprite.Rotation = &n.rotation
 	if extra, ok := n.data.ExtraData.(*leveldata.AngleElemExtra); ok {
 		n.sprite.FlipHorizontal = extra.FlipHorizontally
 	}
 	scene.AddGraphics(n.sprite)
+	if info := theSchemaTileset.ElemByClass(n.data.TileClass); info.IsConfigurable() {
+		// Configurable transforms share the same sprite,
+		// so the op and scope are printed on top of it.
+		n.label = scene.NewLabel(FontLCDTiny)
+		n.label.Text = n.data.ExtraData.(*leveldata.TransformElemExtra).Label()
+		n.label.Pos.Base = &n.data.Pos
+		n.label.Width = 96
+		n.label.Height = 96
+		n.label.AlignHorizontal = ge.AlignHorizontalCenter
+		n.label.AlignVertical = ge.AlignVerticalCenter
+		n.label.ColorScale.SetColor(defaultLCDColor)
+		scene.AddGraphics(n.label)
+	}
 	if n.shaderEnabled {
 		n.sprite.Shader = scene.NewShader(ShaderVideoDistortion)
 		n.shaderStep = scene.Rand().IntRange(1, 4)
@@ -52,6 +66,14 @@ func (n *schemaElemNode) IsDisposed() bool {
 }
 
 func (n *schemaElemNode) Update(delta float64) {
+	visible := n.view.IsVisible(n.data.Pos)
+	n.sprite.Pos.Offset = n.view.Offset()
+	n.sprite.Visible = visible
+	if n.label != nil {
+		n.label.Pos.Offset = n.view.Offset().Sub(gmath.Vec{X: 48, Y: 48})
+		n.label.Visible = visible
+	}
+
 	if !n.shaderEnabled {
 		return
 	}
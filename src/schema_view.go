@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/quasilyte/decipherism-game/leveldata"
+	"github.com/quasilyte/gmath"
+)
+
+// schemaView is a part of the screen that displays the component schema.
+//
+// The view can fit the default grid size; bigger schemas
+// are scrolled by whole cells, the elements outside of the view are hidden.
+type schemaView struct {
+	rect      gmath.Rect
+	size      gmath.Vec
+	scroll    gmath.Vec
+	maxScroll gmath.Vec
+}
+
+func newSchemaView(pos gmath.Vec, cellSize float64, schema *leveldata.ComponentSchema) *schemaView {
+	viewSize := gmath.Vec{
+		X: float64(leveldata.DefaultNumSchemaCols) * cellSize,
+		Y: float64(leveldata.DefaultNumSchemaRows) * cellSize,
+	}
+	schemaSize := gmath.Vec{
+		X: float64(schema.NumCols) * cellSize,
+		Y: float64(schema.NumRows) * cellSize,
+	}
+	return &schemaView{
+		rect: gmath.Rect{Min: pos, Max: pos.Add(viewSize)},
+		size: gmath.Vec{
+			X: gmath.ClampMax(schemaSize.X, viewSize.X),
+			Y: gmath.ClampMax(schemaSize.Y, viewSize.Y),
+		},
+		maxScroll: gmath.Vec{
+			X: gmath.ClampMin(schemaSize.X-viewSize.X, 0),
+			Y: gmath.ClampMin(schemaSize.Y-viewSize.Y, 0),
+		},
+	}
+}
+
+func (v *schemaView) IsScrollable() bool {
+	return !v.maxScroll.IsZero()
+}
+
+// Size returns the size of the displayed schema area.
+// It's smaller than the view for the schemas that use a smaller grid.
+func (v *schemaView) Size() gmath.Vec {
+	return v.size
+}
+
+// Offset returns a translation that should be applied to the schema objects.
+func (v *schemaView) Offset() gmath.Vec {
+	return v.scroll.Neg()
+}
+
+// IsVisible reports whether a schema point is inside the view.
+func (v *schemaView) IsVisible(pos gmath.Vec) bool {
+	return v.rect.Contains(pos.Sub(v.scroll))
+}
+
+func (v *schemaView) Scroll(delta gmath.Vec) {
+	v.scroll.X = gmath.Clamp(v.scroll.X+delta.X, 0, v.maxScroll.X)
+	v.scroll.Y = gmath.Clamp(v.scroll.Y+delta.Y, 0, v.maxScroll.Y)
+}
@@ -8,6 +8,7 @@ import (
 
 type signalNode struct {
 	pos    gmath.Vec
+	view   *schemaView
 	sprite *ge.Sprite
 	dst    gmath.Vec
 	speed  float64
@@ -15,9 +16,10 @@ type signalNode struct {
 	EventDestinationReached gesignal.Event[*signalNode]
 }
 
-func newSignalNode(pos gmath.Vec) *signalNode {
+func newSignalNode(pos gmath.Vec, view *schemaView) *signalNode {
 	return &signalNode{
 		pos:   pos,
+		view:  view,
 		speed: 160,
 	}
 }
@@ -37,6 +39,9 @@ func (s *signalNode) Dispose() {
 }
 
 func (s *signalNode) Update(delta float64) {
+	s.sprite.Pos.Offset = s.view.Offset()
+	s.sprite.Visible = s.view.IsVisible(s.pos)
+
 	if s.dst.IsZero() {
 		return
 	}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/ui"
+	"github.com/quasilyte/gmath"
+)
+
+type simulationMenuController struct {
+	gameState *gameState
+	scene     *ge.Scene
+}
+
+func newSimulationMenuController(s *gameState) *simulationMenuController {
+	return &simulationMenuController{gameState: s}
+}
+
+func (c *simulationMenuController) Init(scene *ge.Scene) {
+	c.scene = scene
+
+	ctx := scene.Context()
+	rect := ge.NewRect(ctx, ctx.WindowWidth, ctx.WindowWidth)
+	rect.Centered = false
+	rect.FillColorScale.SetRGBA(0x14, 0x18, 0x13, 0xff)
+	scene.AddGraphics(rect)
+
+	buttonWidth := 640.0
+	offset := gmath.Vec{X: ctx.WindowWidth/2 - buttonWidth/2, Y: 256 - 64}
+	uiRoot := ui.NewRoot(ctx, c.gameState.input)
+	uiRoot.ActivationAction = ActionMenuConfirm
+	uiRoot.NextInputAction = ActionMenuNext
+	uiRoot.PrevInputAction = ActionMenuPrev
+	scene.AddObject(uiRoot)
+
+	puzzles := &c.gameState.data.Puzzles
+	dailyPuzzle := newDailyPuzzle(time.Now())
+
+	var bgroup buttonGroup
+
+	dailyButton := uiRoot.NewButton(optionsButtonStyle.Resized(buttonWidth, 80))
+	bgroup.AddButton(dailyButton)
+	dailyButton.Text = "daily puzzle"
+	if puzzles.LastDailyDate == dailyPuzzle.date {
+		dailyButton.Text += " (solved)"
+	}
+	dailyButton.Pos.Offset = offset
+	dailyButton.EventActivated.Connect(nil, func(_ *ui.Button) {
+		startPuzzle(scene, c.gameState, dailyPuzzle)
+	})
+	scene.AddObject(dailyButton)
+	offset.Y += 128
+
+	endlessButton := uiRoot.NewButton(optionsButtonStyle.Resized(buttonWidth, 80))
+	bgroup.AddButton(endlessButton)
+	endlessButton.Text = "endless mode"
+	endlessButton.Pos.Offset = offset
+	endlessButton.EventActivated.Connect(nil, func(_ *ui.Button) {
+		startPuzzle(scene, c.gameState, newEndlessPuzzle(puzzles, scene.Rand()))
+	})
+	scene.AddObject(endlessButton)
+	offset.Y += 128
+
+	if runtime.GOARCH != "wasm" {
+		customButton := uiRoot.NewButton(optionsButtonStyle.Resized(buttonWidth, 80))
+		bgroup.AddButton(customButton)
+		customButton.Text = "custom levels"
+		customButton.Pos.Offset = offset
+		customButton.EventActivated.Connect(nil, func(_ *ui.Button) {
+			scene.Context().ChangeScene(newCustomLevelSelectController(c.gameState))
+		})
+		scene.AddObject(customButton)
+		offset.Y += 128
+	}
+
+	backButton := uiRoot.NewButton(optionsButtonStyle.Resized(buttonWidth, 80))
+	bgroup.AddButton(backButton)
+	backButton.Text = "back"
+	backButton.Pos.Offset = offset
+	backButton.EventActivated.Connect(nil, func(_ *ui.Button) {
+		c.leave()
+	})
+	scene.AddObject(backButton)
+	offset.Y += 128
+
+	bgroup.Connect(uiRoot)
+	bgroup.FocusFirst()
+
+	stats := scene.NewLabel(FontLCDSmall)
+	stats.ColorScale.SetColor(defaultLCDColor)
+	stats.Text = fmt.Sprintf("daily streak: %d (best: %d)\nendless streak: %d (best: %d)",
+		puzzles.currentDailyStreak(dailyPuzzle.date), puzzles.BestDailyStreak,
+		puzzles.EndlessStreak, puzzles.BestEndlessStreak)
+	if puzzles.BestDailyTime != 0 {
+		stats.Text += "\nbest daily time: " + formatPuzzleTime(puzzles.BestDailyTime)
+	}
+	stats.Pos.Offset = offset
+	stats.Width = buttonWidth
+	stats.AlignHorizontal = ge.AlignHorizontalCenter
+	scene.AddGraphics(stats)
+}
+
+func (c *simulationMenuController) leave() {
+	c.scene.Context().ChangeScene(newMainMenuController(c.gameState))
+}
+
+func (c *simulationMenuController) Update(delta float64) {
+	if c.gameState.input.ActionIsJustPressed(ActionLeave) {
+		c.leave()
+	}
+}
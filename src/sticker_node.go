@@ -6,7 +6,8 @@ import (
 )
 
 type stickerNode struct {
-	pos gmath.Vec
+	pos  gmath.Vec
+	view *schemaView
 
 	sprite *ge.Sprite
 	label  *ge.Label
@@ -14,9 +15,10 @@ type stickerNode struct {
 	text string
 }
 
-func newStickerNode(pos gmath.Vec, text string) *stickerNode {
+func newStickerNode(pos gmath.Vec, view *schemaView, text string) *stickerNode {
 	return &stickerNode{
 		pos:  pos,
+		view: view,
 		text: text,
 	}
 }
@@ -29,7 +31,6 @@ func (s *stickerNode) Init(scene *ge.Scene) {
 
 	s.label = scene.NewLabel(FontHandwrittenSmall)
 	s.label.Pos.Base = &s.pos
-	s.label.Pos.Offset = gmath.Vec{X: 34, Y: 72}
 	s.label.Text = s.text
 	s.label.ColorScale.SetRGBA(30, 30, 60, 220)
 	scene.AddGraphics(s.label)
@@ -37,4 +38,10 @@ func (s *stickerNode) Init(scene *ge.Scene) {
 
 func (s *stickerNode) IsDisposed() bool { return false }
 
-func (s *stickerNode) Update(delta float64) {}
+func (s *stickerNode) Update(delta float64) {
+	visible := s.view.IsVisible(s.pos)
+	s.sprite.Pos.Offset = s.view.Offset()
+	s.sprite.Visible = visible
+	s.label.Pos.Offset = s.view.Offset().Add(gmath.Vec{X: 34, Y: 72})
+	s.label.Visible = visible
+}
@@ -1,25 +1,9 @@
 package main
 
-import (
-	"hash/fnv"
+import "github.com/quasilyte/decipherism-game/leveldata"
 
-	"github.com/quasilyte/decipherism-game/leveldata"
-	"github.com/quasilyte/ge"
-	"github.com/quasilyte/ge/tiled"
-)
-
-func loadLevelTemplate(scene *ge.Scene, levelData []byte) (*leveldata.SchemaTemplate, error) {
-	tileset, err := tiled.UnmarshalTileset(scene.LoadRaw(RawComponentSchemaTilesetJSON).Data)
-	if err != nil {
-		panic(err)
-	}
-	return leveldata.LoadLevelTemplate(tileset, levelData)
-}
-
-func fnvhash(b []byte) uint64 {
-	hash := fnv.New64a()
-	hash.Write(b)
-	return hash.Sum64()
+func loadLevelTemplate(levelData []byte) (*leveldata.SchemaTemplate, error) {
+	return leveldata.LoadLevelTemplate(theSchemaTileset, levelData)
 }
 
 func volumeMultiplier(level int) float64 {